@@ -0,0 +1,134 @@
+package checkpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FingerprintSize is the number of leading bytes of a file hashed to identify it
+const FingerprintSize = 4096
+
+// Entry records how far an input file has been delivered
+type Entry struct {
+	// Fingerprint is the hex SHA-256 of the first FingerprintBytes bytes of the file
+	Fingerprint      string    `json:"fingerprint"`
+	FingerprintBytes int       `json:"fingerprintBytes"`
+	Offset           int64     `json:"offset"`
+	Line             int       `json:"line"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// State is the content of a checkpoint file, with one entry per input keyed by absolute path
+type State struct {
+	Files map[string]Entry `json:"files"`
+}
+
+// File is a checkpoint file. Saving rewrites it atomically so that a crash never
+// leaves a partial state behind. It is safe for concurrent use.
+type File struct {
+	mu    sync.Mutex
+	path  string
+	state State
+}
+
+// Open loads the checkpoint file at path, starting from an empty state when it does not exist yet
+func Open(path string) (*File, error) {
+	f := &File{path: path, state: State{Files: make(map[string]Entry)}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, &ReadError{Path: path, Err: err}
+	}
+	if err := json.Unmarshal(data, &f.state); err != nil {
+		return nil, &ReadError{Path: path, Err: err}
+	}
+	if f.state.Files == nil {
+		f.state.Files = make(map[string]Entry)
+	}
+	return f, nil
+}
+
+// Get returns the entry of the input at filePath, if there is one
+func (f *File) Get(filePath string) (Entry, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry, ok := f.state.Files[key(filePath)]
+	return entry, ok
+}
+
+// Save records entry for the input at filePath and writes the checkpoint file
+func (f *File) Save(filePath string, entry Entry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry.UpdatedAt = time.Now().UTC()
+	f.state.Files[key(filePath)] = entry
+
+	data, err := json.MarshalIndent(f.state, "", "  ")
+	if err != nil {
+		return &WriteError{Path: f.path, Err: err}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp-*")
+	if err != nil {
+		return &WriteError{Path: f.path, Err: err}
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return &WriteError{Path: f.path, Err: err}
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return &WriteError{Path: f.path, Err: err}
+	}
+	if err := tmp.Close(); err != nil {
+		return &WriteError{Path: f.path, Err: err}
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return &WriteError{Path: f.path, Err: err}
+	}
+	return nil
+}
+
+// Path returns the path of the checkpoint file
+func (f *File) Path() string {
+	return f.path
+}
+
+// Fingerprint hashes the first size bytes of the file at filePath. Files shorter than
+// size are hashed whole, and the number of bytes hashed is returned with the hash.
+func Fingerprint(filePath string, size int) (string, int, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	n, err := io.CopyN(hash, file, int64(size))
+	if err != nil && err != io.EOF {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), int(n), nil
+}
+
+// Matches reports whether the file at filePath is the file entry was recorded for
+func (e Entry) Matches(filePath string) bool {
+	fingerprint, n, err := Fingerprint(filePath, e.FingerprintBytes)
+	return err == nil && n == e.FingerprintBytes && fingerprint == e.Fingerprint
+}
+
+// key identifies an input by its absolute path so relative and absolute arguments agree
+func key(filePath string) string {
+	if abs, err := filepath.Abs(filePath); err == nil {
+		return abs
+	}
+	return filepath.Clean(filePath)
+}
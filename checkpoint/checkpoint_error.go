@@ -0,0 +1,31 @@
+package checkpoint
+
+import "fmt"
+
+// ReadError represents an error when an existing checkpoint file cannot be read or decoded
+type ReadError struct {
+	Path string
+	Err  error
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("failed to read checkpoint file %s: %v", e.Path, e.Err)
+}
+
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// WriteError represents an error when the checkpoint file cannot be written
+type WriteError struct {
+	Path string
+	Err  error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("failed to write checkpoint file %s: %v", e.Path, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
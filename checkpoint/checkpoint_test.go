@@ -0,0 +1,95 @@
+package checkpoint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestSaveAndOpen(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "state.json")
+	f, err := Open(statePath)
+	if err != nil {
+		t.Fatalf("Open of a missing checkpoint file returned error: %v", err)
+	}
+	if _, ok := f.Get("input.jsonl"); ok {
+		t.Error("Expected no entry in a new checkpoint file")
+	}
+
+	input := filepath.Join(dir, "input.jsonl")
+	if err := f.Save(input, Entry{Fingerprint: "abc", FingerprintBytes: 3, Offset: 42, Line: 7}); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	reopened, err := Open(statePath)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	entry, ok := reopened.Get(input)
+	if !ok || entry.Offset != 42 || entry.Line != 7 || entry.Fingerprint != "abc" || entry.UpdatedAt.IsZero() {
+		t.Errorf("Expected saved entry to be read back, got %+v (found=%v)", entry, ok)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	rel, err := filepath.Rel(wd, input)
+	if err != nil {
+		t.Fatalf("failed to make %s relative: %v", input, err)
+	}
+	if _, ok := reopened.Get(rel); !ok {
+		t.Error("Expected a relative path to find the entry saved under the absolute path")
+	}
+
+	if matches, _ := filepath.Glob(statePath + ".tmp-*"); len(matches) != 0 {
+		t.Errorf("Expected no temporary files to be left behind, got %v", matches)
+	}
+}
+
+func TestOpenCorruptFile(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	writeFile(t, statePath, "{not json")
+	_, err := Open(statePath)
+	var readErr *ReadError
+	if !errors.As(err, &readErr) || readErr.Path != statePath {
+		t.Errorf("Expected ReadError for %s, got %T: %v", statePath, err, err)
+	}
+}
+
+func TestEntryMatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.jsonl")
+	writeFile(t, path, "line one\nline two\n")
+	fingerprint, n, err := Fingerprint(path, FingerprintSize)
+	if err != nil {
+		t.Fatalf("Fingerprint returned error: %v", err)
+	}
+	if n != 18 {
+		t.Errorf("Expected a short file to be hashed whole, hashed %d bytes", n)
+	}
+	entry := Entry{Fingerprint: fingerprint, FingerprintBytes: n}
+
+	writeFile(t, path, "line one\nline two\nline three\n")
+	if !entry.Matches(path) {
+		t.Error("Expected a file that grew to match its checkpoint")
+	}
+	writeFile(t, path, "line 1\nline two\nline three\n")
+	if entry.Matches(path) {
+		t.Error("Expected a file whose start changed not to match")
+	}
+	writeFile(t, path, "line one\n")
+	if entry.Matches(path) {
+		t.Error("Expected a file shorter than the fingerprint not to match")
+	}
+	if entry.Matches(path + ".missing") {
+		t.Error("Expected a missing file not to match")
+	}
+}
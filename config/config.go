@@ -1,9 +1,52 @@
 package config
 
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
 const (
 	DEFAULT_OTEL_ENDPOINT         = "http://localhost:4318/v1/traces"
 	DEFAULT_OTEL_LOGS_ENDPOINT    = "http://localhost:4318/v1/logs"
 	DEFAULT_OTEL_METRICS_ENDPOINT = "http://localhost:4318/v1/metrics"
+	DEFAULT_OTEL_GRPC_ENDPOINT    = "localhost:4317"
+)
+
+const (
+	COMPRESSION_NONE = "none"
+	COMPRESSION_GZIP = "gzip"
+	COMPRESSION_ZSTD = "zstd"
+)
+
+const (
+	AUTH_NONE    = "none"
+	AUTH_BEARER  = "bearer"
+	AUTH_BASIC   = "basic"
+	AUTH_API_KEY = "api-key"
+
+	DEFAULT_API_KEY_HEADER = "X-API-Key"
+)
+
+const (
+	PROTOCOL_HTTP = "http"
+	PROTOCOL_GRPC = "grpc"
+)
+
+const (
+	ENCODING_JSON     = "json"
+	ENCODING_PROTOBUF = "protobuf"
+)
+
+const (
+	EXPORTER_OTLP   = "otlp"
+	EXPORTER_FILE   = "file"
+	EXPORTER_STDOUT = "stdout"
+)
+
+const (
+	REBASE_ANCHOR_NEWEST = "newest"
+	REBASE_ANCHOR_OLDEST = "oldest"
 )
 
 // Config holds the configuration for the telemetry ingestion
@@ -12,9 +55,61 @@ type Config struct {
 	OtelEndpoint        string
 	OtelLogsEndpoint    string
 	OtelMetricsEndpoint string
+	Protocol            string
+	Exporter            string
+	ExportFile          string
+	OTLPEncoding        string
+	Compression         string
 	MaxBufferCapacity   int
 	SendAll             bool
 	Workers             int
+	QueueSize           int
+	MaxRetries          int
+	RetryInitialBackoff time.Duration
+	RetryMaxBackoff     time.Duration
+	Headers             []string
+	TracesHeaders       []string
+	LogsHeaders         []string
+	MetricsHeaders      []string
+	AuthMode            string
+	AuthUsername        string
+	AuthSecretEnv       string
+	AuthSecretFile      string
+	APIKeyHeader        string
+	TLSCAFile           string
+	TLSCertFile         string
+	TLSKeyFile          string
+	TLSServerName       string
+	InsecureSkipVerify  bool
+	ConnectTimeout      time.Duration
+	ResponseTimeout     time.Duration
+	RequestTimeout      time.Duration
+	ShutdownGracePeriod time.Duration
+	Batch               bool
+	BatchMaxItems       int
+	BatchMaxBytes       int
+	BatchFlushInterval  time.Duration
+	MaxRequestBytes     int
+	DeadLetterPath      string
+	BreakerThreshold    int
+	BreakerCooldown     time.Duration
+	DryRun              bool
+	Recursive           bool
+	FailFast            bool
+	Follow              bool
+	FollowInterval      time.Duration
+	CheckpointPath      string
+	CheckpointInterval  time.Duration
+	Resume              bool
+	RebaseTimestamps    bool
+	RebaseAnchor        string
+	RebaseTo            string
+	ReplayPacing        bool
+	ReplaySpeed         float64
+	ReplayMaxGap        time.Duration
+	RegenerateIDs       bool
+	RecordedEndpoints   bool
+	ConfiguredEndpoints bool
 }
 
 // NewConfig creates a new Config with default values
@@ -24,8 +119,136 @@ func NewConfig() *Config {
 		OtelEndpoint:        DEFAULT_OTEL_ENDPOINT,
 		OtelLogsEndpoint:    DEFAULT_OTEL_LOGS_ENDPOINT,
 		OtelMetricsEndpoint: DEFAULT_OTEL_METRICS_ENDPOINT,
+		Protocol:            PROTOCOL_HTTP,
+		Exporter:            EXPORTER_OTLP,
+		OTLPEncoding:        ENCODING_JSON,
+		Compression:         COMPRESSION_NONE,
 		MaxBufferCapacity:   1024 * 1024,
 		SendAll:             false,
 		Workers:             10,
+		QueueSize:           1000,
+		MaxRetries:          3,
+		RetryInitialBackoff: 500 * time.Millisecond,
+		RetryMaxBackoff:     30 * time.Second,
+		AuthMode:            AUTH_NONE,
+		APIKeyHeader:        DEFAULT_API_KEY_HEADER,
+		ConnectTimeout:      10 * time.Second,
+		ResponseTimeout:     30 * time.Second,
+		RequestTimeout:      60 * time.Second,
+		ShutdownGracePeriod: 10 * time.Second,
+		Batch:               false,
+		BatchMaxItems:       512,
+		BatchMaxBytes:       1024 * 1024,
+		BatchFlushInterval:  time.Second,
+		BreakerThreshold:    5,
+		BreakerCooldown:     30 * time.Second,
+		FollowInterval:      time.Second,
+		CheckpointInterval:  5 * time.Second,
+		RebaseAnchor:        REBASE_ANCHOR_NEWEST,
+		ReplaySpeed:         1,
+	}
+}
+
+// Validate checks that the enumerated options hold supported values.
+// Empty values are accepted and fall back to the defaults.
+func (c *Config) Validate() error {
+	switch c.Protocol {
+	case "", PROTOCOL_HTTP, PROTOCOL_GRPC:
+	default:
+		return &InvalidOptionError{Option: "protocol", Value: c.Protocol}
+	}
+	switch c.Exporter {
+	case "", EXPORTER_OTLP, EXPORTER_STDOUT:
+	case EXPORTER_FILE:
+		if c.ExportFile == "" {
+			return &MissingOptionError{Option: "export-file", RequiredBy: "--exporter file"}
+		}
+	default:
+		return &InvalidOptionError{Option: "exporter", Value: c.Exporter}
+	}
+	switch c.OTLPEncoding {
+	case "", ENCODING_JSON, ENCODING_PROTOBUF:
+	default:
+		return &InvalidOptionError{Option: "otlp-encoding", Value: c.OTLPEncoding}
+	}
+	switch c.Compression {
+	case "", COMPRESSION_NONE, COMPRESSION_GZIP:
+	case COMPRESSION_ZSTD:
+		// grpc-go only ships a gzip compressor
+		if c.Protocol == PROTOCOL_GRPC {
+			return &InvalidOptionError{Option: "compression", Value: c.Compression}
+		}
+	default:
+		return &InvalidOptionError{Option: "compression", Value: c.Compression}
+	}
+	switch c.AuthMode {
+	case "", AUTH_NONE, AUTH_BEARER, AUTH_BASIC, AUTH_API_KEY:
+	default:
+		return &InvalidOptionError{Option: "auth", Value: c.AuthMode}
+	}
+	switch c.RebaseAnchor {
+	case "", REBASE_ANCHOR_NEWEST, REBASE_ANCHOR_OLDEST:
+	default:
+		return &InvalidOptionError{Option: "rebase-anchor", Value: c.RebaseAnchor}
+	}
+	if _, err := c.RebaseTarget(time.Time{}); err != nil {
+		return &InvalidOptionError{Option: "rebase-to", Value: c.RebaseTo}
+	}
+	// The speed divides every paced gap, so it must be a positive number
+	if c.ReplayPacing && !(c.ReplaySpeed > 0) {
+		return &InvalidOptionError{Option: "speed", Value: strconv.FormatFloat(c.ReplaySpeed, 'g', -1, 64)}
+	}
+	if c.Resume && c.CheckpointPath == "" {
+		return &MissingOptionError{Option: "checkpoint", RequiredBy: "--resume"}
+	}
+	// Offsets in a followed file restart on truncation and rotation
+	if c.Follow && c.CheckpointPath != "" {
+		return &ConflictingOptionsError{Option: "checkpoint", ConflictsWith: "follow"}
+	}
+	// Last mode reads the whole input on every run, there is no delivery progress to record
+	if c.CheckpointPath != "" && !c.SendAll {
+		return &MissingOptionError{Option: "sendAll", RequiredBy: "--checkpoint"}
+	}
+	// The newest and oldest timestamps are only known once the whole input was read
+	if c.Follow && c.RebaseTimestamps {
+		return &ConflictingOptionsError{Option: "rebase-timestamps", ConflictsWith: "follow"}
+	}
+	return nil
+}
+
+// RebaseTarget returns the instant --rebase-to names, or now when it is not set
+func (c *Config) RebaseTarget(now time.Time) (time.Time, error) {
+	if c.RebaseTo == "" {
+		return now, nil
+	}
+	return time.Parse(time.RFC3339Nano, c.RebaseTo)
+}
+
+// TLSConfigured reports whether any TLS option was set explicitly
+func (c *Config) TLSConfigured() bool {
+	return c.TLSCAFile != "" || c.TLSCertFile != "" || c.TLSKeyFile != "" || c.TLSServerName != "" || c.InsecureSkipVerify
+}
+
+// UseGRPCDefaults points every endpoint that was not set explicitly at the default OTLP/gRPC port
+func (c *Config) UseGRPCDefaults(isSet func(option string) bool) {
+	if !isSet("traces-endpoint") {
+		c.OtelEndpoint = DEFAULT_OTEL_GRPC_ENDPOINT
+	}
+	if !isSet("logs-endpoint") {
+		c.OtelLogsEndpoint = DEFAULT_OTEL_GRPC_ENDPOINT
+	}
+	if !isSet("metrics-endpoint") {
+		c.OtelMetricsEndpoint = DEFAULT_OTEL_GRPC_ENDPOINT
+	}
+}
+
+// SplitEndpoints returns the destinations listed in a comma-separated endpoint option
+func SplitEndpoints(value string) []string {
+	var endpoints []string
+	for _, endpoint := range strings.Split(value, ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
 	}
+	return endpoints
 }
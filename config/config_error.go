@@ -0,0 +1,65 @@
+package config
+
+import "fmt"
+
+// InvalidOptionError represents a configuration option set to an unsupported value
+type InvalidOptionError struct {
+	Option string
+	Value  string
+}
+
+func (e *InvalidOptionError) Error() string {
+	return fmt.Sprintf("invalid value %q for option --%s", e.Value, e.Option)
+}
+
+// InvalidHeaderError represents a header flag that is not in key=value form
+type InvalidHeaderError struct {
+	Header string
+}
+
+func (e *InvalidHeaderError) Error() string {
+	return fmt.Sprintf("invalid header %q, expected key=value", e.Header)
+}
+
+// MissingSecretError represents an authentication mode without a usable secret
+type MissingSecretError struct {
+	Source string
+}
+
+func (e *MissingSecretError) Error() string {
+	return fmt.Sprintf("authentication secret not found in %s", e.Source)
+}
+
+// SecretReadError represents an error when reading the authentication secret file fails
+type SecretReadError struct {
+	FilePath string
+	Err      error
+}
+
+func (e *SecretReadError) Error() string {
+	return fmt.Sprintf("failed to read secret file %s: %v", e.FilePath, e.Err)
+}
+
+func (e *SecretReadError) Unwrap() error {
+	return e.Err
+}
+
+// MissingOptionError represents an option that has to be set because of another option
+type MissingOptionError struct {
+	Option     string
+	RequiredBy string
+}
+
+func (e *MissingOptionError) Error() string {
+	return fmt.Sprintf("option --%s is required by %s", e.Option, e.RequiredBy)
+}
+
+// ConflictingOptionsError represents two options that cannot be used together
+type ConflictingOptionsError struct {
+	Option        string
+	ConflictsWith string
+}
+
+func (e *ConflictingOptionsError) Error() string {
+	return fmt.Sprintf("option --%s cannot be used with --%s", e.Option, e.ConflictsWith)
+}
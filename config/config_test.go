@@ -1,7 +1,10 @@
 package config
 
 import (
+	"fmt"
+	"math"
 	"testing"
+	"time"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -18,6 +21,18 @@ func TestNewConfig(t *testing.T) {
 	if cfg.OtelMetricsEndpoint != DEFAULT_OTEL_METRICS_ENDPOINT {
 		t.Errorf("Expected OtelMetricsEndpoint to be '%s', got '%s'", DEFAULT_OTEL_METRICS_ENDPOINT, cfg.OtelMetricsEndpoint)
 	}
+	if cfg.Protocol != PROTOCOL_HTTP {
+		t.Errorf("Expected Protocol to be '%s', got '%s'", PROTOCOL_HTTP, cfg.Protocol)
+	}
+	if cfg.Exporter != EXPORTER_OTLP {
+		t.Errorf("Expected Exporter to be '%s', got '%s'", EXPORTER_OTLP, cfg.Exporter)
+	}
+	if cfg.OTLPEncoding != ENCODING_JSON {
+		t.Errorf("Expected OTLPEncoding to be '%s', got '%s'", ENCODING_JSON, cfg.OTLPEncoding)
+	}
+	if cfg.Compression != COMPRESSION_NONE {
+		t.Errorf("Expected Compression to be '%s', got '%s'", COMPRESSION_NONE, cfg.Compression)
+	}
 	if cfg.MaxBufferCapacity != 1024*1024 {
 		t.Errorf("Expected MaxBufferCapacity to be %d, got %d", 1024*1024, cfg.MaxBufferCapacity)
 	}
@@ -27,4 +42,199 @@ func TestNewConfig(t *testing.T) {
 	if cfg.Workers != 10 {
 		t.Errorf("Expected Workers to be 10, got %d", cfg.Workers)
 	}
+	if cfg.MaxRetries != 3 {
+		t.Errorf("Expected MaxRetries to be 3, got %d", cfg.MaxRetries)
+	}
+	if cfg.RetryInitialBackoff != 500*time.Millisecond || cfg.RetryMaxBackoff != 30*time.Second {
+		t.Errorf("Expected retry backoff 500ms..30s, got %v..%v", cfg.RetryInitialBackoff, cfg.RetryMaxBackoff)
+	}
+	if cfg.ConnectTimeout != 10*time.Second || cfg.ResponseTimeout != 30*time.Second || cfg.RequestTimeout != 60*time.Second {
+		t.Errorf("Expected timeouts 10s/30s/60s, got %v/%v/%v", cfg.ConnectTimeout, cfg.ResponseTimeout, cfg.RequestTimeout)
+	}
+	if cfg.ShutdownGracePeriod != 10*time.Second {
+		t.Errorf("Expected ShutdownGracePeriod to be 10s, got %v", cfg.ShutdownGracePeriod)
+	}
+	if cfg.Batch || cfg.BatchMaxItems != 512 || cfg.BatchMaxBytes != 1024*1024 || cfg.BatchFlushInterval != time.Second {
+		t.Errorf("Unexpected batch defaults: batch=%v items=%d bytes=%d interval=%v", cfg.Batch, cfg.BatchMaxItems, cfg.BatchMaxBytes, cfg.BatchFlushInterval)
+	}
+	if cfg.MaxRequestBytes != 0 {
+		t.Errorf("Expected request splitting to be disabled by default, got %d", cfg.MaxRequestBytes)
+	}
+	if cfg.BreakerThreshold != 5 || cfg.BreakerCooldown != 30*time.Second {
+		t.Errorf("Expected circuit breaker defaults 5 failures/30s, got %d/%v", cfg.BreakerThreshold, cfg.BreakerCooldown)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected default config to be valid, got %v", err)
+	}
+	cfg.OTLPEncoding = ENCODING_PROTOBUF
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected protobuf encoding to be valid, got %v", err)
+	}
+	cfg.OTLPEncoding = "xml"
+	err := cfg.Validate()
+	if _, ok := err.(*InvalidOptionError); !ok {
+		t.Errorf("Expected InvalidOptionError for unsupported encoding, got %T: %v", err, err)
+	}
+	cfg = NewConfig()
+	cfg.Protocol = "udp"
+	err = cfg.Validate()
+	if _, ok := err.(*InvalidOptionError); !ok {
+		t.Errorf("Expected InvalidOptionError for unsupported protocol, got %T: %v", err, err)
+	}
+	cfg = NewConfig()
+	cfg.Compression = "brotli"
+	err = cfg.Validate()
+	if _, ok := err.(*InvalidOptionError); !ok {
+		t.Errorf("Expected InvalidOptionError for unsupported compression, got %T: %v", err, err)
+	}
+	cfg.Compression = COMPRESSION_ZSTD
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected zstd to be valid over http, got %v", err)
+	}
+	cfg.Protocol = PROTOCOL_GRPC
+	err = cfg.Validate()
+	if _, ok := err.(*InvalidOptionError); !ok {
+		t.Errorf("Expected InvalidOptionError for zstd over grpc, got %T: %v", err, err)
+	}
+	cfg = NewConfig()
+	cfg.AuthMode = "oauth"
+	err = cfg.Validate()
+	if _, ok := err.(*InvalidOptionError); !ok {
+		t.Errorf("Expected InvalidOptionError for unsupported auth mode, got %T: %v", err, err)
+	}
+}
+
+func TestConfigValidateExporter(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Exporter = EXPORTER_STDOUT
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected stdout exporter to be valid, got %v", err)
+	}
+	cfg.Exporter = EXPORTER_FILE
+	if _, ok := cfg.Validate().(*MissingOptionError); !ok {
+		t.Errorf("Expected MissingOptionError for file exporter without --export-file, got %v", cfg.Validate())
+	}
+	cfg.ExportFile = "out.jsonl"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected file exporter with --export-file to be valid, got %v", err)
+	}
+	cfg.Exporter = "kafka"
+	if _, ok := cfg.Validate().(*InvalidOptionError); !ok {
+		t.Errorf("Expected InvalidOptionError for unsupported exporter, got %v", cfg.Validate())
+	}
+}
+
+func TestUseGRPCDefaults(t *testing.T) {
+	cfg := NewConfig()
+	cfg.OtelLogsEndpoint = "collector:4317"
+	cfg.UseGRPCDefaults(func(option string) bool { return option == "logs-endpoint" })
+	if cfg.OtelEndpoint != DEFAULT_OTEL_GRPC_ENDPOINT {
+		t.Errorf("Expected traces endpoint '%s', got '%s'", DEFAULT_OTEL_GRPC_ENDPOINT, cfg.OtelEndpoint)
+	}
+	if cfg.OtelLogsEndpoint != "collector:4317" {
+		t.Errorf("Expected explicit logs endpoint to be kept, got '%s'", cfg.OtelLogsEndpoint)
+	}
+	if cfg.OtelMetricsEndpoint != DEFAULT_OTEL_GRPC_ENDPOINT {
+		t.Errorf("Expected metrics endpoint '%s', got '%s'", DEFAULT_OTEL_GRPC_ENDPOINT, cfg.OtelMetricsEndpoint)
+	}
+}
+
+func TestTLSConfigured(t *testing.T) {
+	cfg := NewConfig()
+	if cfg.TLSConfigured() {
+		t.Error("Expected default config to have no TLS options")
+	}
+	cfg.TLSServerName = "collector.internal"
+	if !cfg.TLSConfigured() {
+		t.Error("Expected TLS server name to count as a TLS option")
+	}
+}
+
+func TestSplitEndpoints(t *testing.T) {
+	tests := map[string]string{
+		"http://a:4318/v1/traces":                          "[http://a:4318/v1/traces]",
+		"http://a:4318/v1/traces, http://b:4318/v1/traces": "[http://a:4318/v1/traces http://b:4318/v1/traces]",
+		"a:4317,,b:4317,":                                  "[a:4317 b:4317]",
+		"":                                                 "[]",
+	}
+	for value, expected := range tests {
+		if got := fmt.Sprint(SplitEndpoints(value)); got != expected {
+			t.Errorf("SplitEndpoints(%q) = %s, want %s", value, got, expected)
+		}
+	}
+}
+
+func TestConfigValidateCheckpoint(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Resume = true
+	if _, ok := cfg.Validate().(*MissingOptionError); !ok {
+		t.Errorf("Expected MissingOptionError for --resume without --checkpoint, got %v", cfg.Validate())
+	}
+	cfg.CheckpointPath = "state.json"
+	if err, ok := cfg.Validate().(*MissingOptionError); !ok || err.Option != "sendAll" {
+		t.Errorf("Expected MissingOptionError for --checkpoint without --sendAll, got %v", cfg.Validate())
+	}
+	cfg.SendAll = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected --resume with --checkpoint to be valid, got %v", err)
+	}
+	cfg.Follow = true
+	if _, ok := cfg.Validate().(*ConflictingOptionsError); !ok {
+		t.Errorf("Expected ConflictingOptionsError for --checkpoint with --follow, got %v", cfg.Validate())
+	}
+}
+
+func TestConfigValidateRebase(t *testing.T) {
+	cfg := NewConfig()
+	cfg.RebaseTimestamps = true
+	cfg.RebaseTo = "2024-05-01T12:00:00Z"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected --rebase-to with an RFC 3339 time to be valid, got %v", err)
+	}
+	cfg.RebaseTo = "yesterday"
+	if _, ok := cfg.Validate().(*InvalidOptionError); !ok {
+		t.Errorf("Expected InvalidOptionError for --rebase-to yesterday, got %v", cfg.Validate())
+	}
+	cfg.RebaseTo = ""
+	cfg.RebaseAnchor = "middle"
+	if _, ok := cfg.Validate().(*InvalidOptionError); !ok {
+		t.Errorf("Expected InvalidOptionError for --rebase-anchor middle, got %v", cfg.Validate())
+	}
+	cfg.RebaseAnchor = REBASE_ANCHOR_OLDEST
+	cfg.Follow = true
+	if _, ok := cfg.Validate().(*ConflictingOptionsError); !ok {
+		t.Errorf("Expected ConflictingOptionsError for --rebase-timestamps with --follow, got %v", cfg.Validate())
+	}
+}
+
+func TestRebaseTarget(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	if target, err := (&Config{}).RebaseTarget(now); err != nil || !target.Equal(now) {
+		t.Errorf("Expected an unset --rebase-to to target now, got %v (%v)", target, err)
+	}
+	want := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	if target, err := (&Config{RebaseTo: "2024-05-01T12:00:00.0000005Z"}).RebaseTarget(now); err != nil || !target.Equal(want) {
+		t.Errorf("Expected --rebase-to to target %v, got %v (%v)", want, target, err)
+	}
+}
+
+func TestConfigValidateSpeed(t *testing.T) {
+	cfg := NewConfig()
+	cfg.ReplayPacing = true
+	for _, speed := range []float64{0.5, 1, 10} {
+		cfg.ReplaySpeed = speed
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected --speed %v to be valid, got %v", speed, err)
+		}
+	}
+	for _, speed := range []float64{0, -1, math.NaN()} {
+		cfg.ReplaySpeed = speed
+		if _, ok := cfg.Validate().(*InvalidOptionError); !ok {
+			t.Errorf("Expected InvalidOptionError for --speed %v, got %v", speed, cfg.Validate())
+		}
+	}
 }
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"encoding/base64"
+	"net/http"
+	"os"
+	"strings"
+
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// RequestHeaders returns the headers attached to every request for the telemetry type,
+// keyed by their canonical name. Global --header values are overridden by the per-signal
+// ones whatever their case, and the authentication header is applied last.
+func (c *Config) RequestHeaders(telemetryType s.TelemetryType) (map[string]string, error) {
+	headers := make(map[string]string)
+	if err := parseHeaders(c.Headers, headers); err != nil {
+		return nil, err
+	}
+	if err := parseHeaders(c.signalHeaders(telemetryType), headers); err != nil {
+		return nil, err
+	}
+
+	if c.AuthMode == "" || c.AuthMode == AUTH_NONE {
+		return headers, nil
+	}
+	secret, err := c.readSecret()
+	if err != nil {
+		return nil, err
+	}
+	switch c.AuthMode {
+	case AUTH_BEARER:
+		headers["Authorization"] = "Bearer " + secret
+	case AUTH_BASIC:
+		credentials := base64.StdEncoding.EncodeToString([]byte(c.AuthUsername + ":" + secret))
+		headers["Authorization"] = "Basic " + credentials
+	case AUTH_API_KEY:
+		name := c.APIKeyHeader
+		if name == "" {
+			name = DEFAULT_API_KEY_HEADER
+		}
+		headers[http.CanonicalHeaderKey(name)] = secret
+	}
+	return headers, nil
+}
+
+func (c *Config) signalHeaders(telemetryType s.TelemetryType) []string {
+	switch telemetryType {
+	case s.TelemetryTraces:
+		return c.TracesHeaders
+	case s.TelemetryLogs:
+		return c.LogsHeaders
+	case s.TelemetryMetrics:
+		return c.MetricsHeaders
+	default:
+		return nil
+	}
+}
+
+// parseHeaders parses key=value pairs into headers keyed by the canonical header name,
+// later pairs win
+func parseHeaders(pairs []string, headers map[string]string) error {
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return &InvalidHeaderError{Header: pair}
+		}
+		headers[http.CanonicalHeaderKey(key)] = strings.TrimSpace(value)
+	}
+	return nil
+}
+
+// readSecret reads the authentication secret from the configured environment variable or file,
+// so that it never has to be passed on the command line
+func (c *Config) readSecret() (string, error) {
+	switch {
+	case c.AuthSecretEnv != "":
+		secret, ok := os.LookupEnv(c.AuthSecretEnv)
+		if !ok || secret == "" {
+			return "", &MissingSecretError{Source: "environment variable " + c.AuthSecretEnv}
+		}
+		return secret, nil
+	case c.AuthSecretFile != "":
+		data, err := os.ReadFile(c.AuthSecretFile)
+		if err != nil {
+			return "", &SecretReadError{FilePath: c.AuthSecretFile, Err: err}
+		}
+		secret := strings.TrimSpace(string(data))
+		if secret == "" {
+			return "", &MissingSecretError{Source: "file " + c.AuthSecretFile}
+		}
+		return secret, nil
+	default:
+		return "", &MissingSecretError{Source: "--auth-secret-env or --auth-secret-file"}
+	}
+}
@@ -0,0 +1,104 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// MetadataField is the top-level field holding the Metadata of a dead-lettered line.
+// Replaying ignores it, so dead-letter files can be fed back as regular input.
+const MetadataField = "deadLetter"
+
+// Metadata records where a dead-lettered payload came from and why it was not delivered
+type Metadata struct {
+	Line          int       `json:"line"`
+	Lines         []int     `json:"lines,omitempty"`
+	TelemetryType string    `json:"telemetryType"`
+	Endpoint      string    `json:"endpoint"`
+	StatusCode    int       `json:"statusCode,omitempty"`
+	Error         string    `json:"error"`
+	FailedAt      time.Time `json:"failedAt"`
+}
+
+// Writer appends undeliverable jobs to a JSON Lines file in the ingestor's input format.
+// It is safe for concurrent use by several workers.
+type Writer struct {
+	mu    sync.Mutex
+	path  string
+	file  *os.File
+	count int
+}
+
+// Open opens the dead-letter file at path for appending, creating it when needed
+func Open(path string) (*Writer, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, &OpenError{Path: path, Err: err}
+	}
+	return &Writer{path: path, file: file}, nil
+}
+
+// Write appends the payload of job as one line, with the status code and error it failed with
+func (w *Writer) Write(job s.TelemetryJob, statusCode int, cause error) error {
+	record := make(map[string]any)
+	switch payload := job.Payload.(type) {
+	case map[string]any:
+		for key, value := range payload {
+			record[key] = value
+		}
+	case s.TelemetryData:
+		for key, value := range payload {
+			record[key] = value
+		}
+	}
+
+	metadata := Metadata{
+		Line:          job.LineNum,
+		TelemetryType: job.TelemetryType.String(),
+		Endpoint:      job.Endpoint,
+		StatusCode:    statusCode,
+		FailedAt:      time.Now().UTC(),
+	}
+	if len(job.Lines) > 1 {
+		metadata.Lines = job.Lines
+	}
+	if cause != nil {
+		metadata.Error = cause.Error()
+	}
+	record[MetadataField] = metadata
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return &WriteError{Path: w.path, Err: err}
+	}
+	line = append(line, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.file.Write(line); err != nil {
+		return &WriteError{Path: w.path, Err: err}
+	}
+	w.count++
+	return nil
+}
+
+// Count returns the number of lines written so far
+func (w *Writer) Count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
+
+// Path returns the path of the dead-letter file
+func (w *Writer) Path() string {
+	return w.path
+}
+
+// Close closes the dead-letter file
+func (w *Writer) Close() error {
+	return w.file.Close()
+}
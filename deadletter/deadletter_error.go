@@ -0,0 +1,31 @@
+package deadletter
+
+import "fmt"
+
+// OpenError represents an error when the dead-letter file cannot be opened
+type OpenError struct {
+	Path string
+	Err  error
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("failed to open dead-letter file %s: %v", e.Path, e.Err)
+}
+
+func (e *OpenError) Unwrap() error {
+	return e.Err
+}
+
+// WriteError represents an error when a failed job cannot be written to the dead-letter file
+type WriteError struct {
+	Path string
+	Err  error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("failed to write to dead-letter file %s: %v", e.Path, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
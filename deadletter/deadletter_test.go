@@ -0,0 +1,122 @@
+package deadletter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+func readRecords(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open dead-letter file: %v", err)
+	}
+	defer file.Close()
+	var records []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("dead-letter line is not valid JSON: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestWriterWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	w, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	job := s.TelemetryJob{
+		Endpoint:      "http://collector/v1/traces",
+		Payload:       map[string]any{"resourceSpans": []any{map[string]any{"scopeSpans": []any{}}}},
+		TelemetryType: s.TelemetryTraces,
+		LineNum:       12,
+	}
+	if err := w.Write(job, 503, errors.New("unavailable")); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	batched := s.TelemetryJob{
+		Endpoint:      "http://collector/v1/logs",
+		Payload:       s.TelemetryData{"resourceLogs": []any{}},
+		TelemetryType: s.TelemetryLogs,
+		LineNum:       3,
+		Lines:         []int{3, 4},
+	}
+	if err := w.Write(batched, 0, errors.New("connection refused")); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	if w.Count() != 2 {
+		t.Errorf("Expected 2 records, got %d", w.Count())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	records := readRecords(t, path)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(records))
+	}
+	if _, ok := records[0]["resourceSpans"]; !ok {
+		t.Error("Expected the payload to be kept in the input format")
+	}
+	meta, _ := records[0][MetadataField].(map[string]any)
+	if meta["line"] != float64(12) || meta["endpoint"] != "http://collector/v1/traces" || meta["statusCode"] != float64(503) || meta["error"] != "unavailable" || meta["telemetryType"] != "Traces" {
+		t.Errorf("Unexpected metadata: %v", meta)
+	}
+	if _, ok := meta["failedAt"]; !ok {
+		t.Error("Expected a failedAt timestamp")
+	}
+	meta, _ = records[1][MetadataField].(map[string]any)
+	if _, ok := meta["statusCode"]; ok {
+		t.Errorf("Expected no status code for a request without a response, got %v", meta["statusCode"])
+	}
+	if lines, _ := meta["lines"].([]any); len(lines) != 2 {
+		t.Errorf("Expected the batched source lines to be recorded, got %v", meta["lines"])
+	}
+}
+
+func TestWriterAppendsAndConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	if err := os.WriteFile(path, []byte(`{"resourceLogs":[]}`+"\n"), 0o644); err != nil {
+		t.Fatalf("failed to seed file: %v", err)
+	}
+	w, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Write(s.TelemetryJob{Payload: map[string]any{"resourceSpans": []any{}}, LineNum: i}, 500, errors.New("failed"))
+		}()
+	}
+	wg.Wait()
+	w.Close()
+	if records := readRecords(t, path); len(records) != 51 {
+		t.Errorf("Expected 51 lines, got %d", len(records))
+	}
+}
+
+func TestOpenError(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "missing", "dead.jsonl"))
+	var openErr *OpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("Expected OpenError, got %T: %v", err, err)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected OpenError to unwrap to os.ErrNotExist, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package exporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/sender"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// DryRunExporter encodes, compresses and splits every job exactly like the OTLP exporter
+// and records what would be sent, but never opens a connection. Over gRPC, requests are
+// measured as protobuf messages, as the gRPC sender counts them.
+type DryRunExporter struct {
+	sender *sender.HTTPSender
+	stats  *stats.SendStats
+	grpc   bool
+}
+
+// NewDryRunExporter creates a DryRunExporter configured from cfg
+func NewDryRunExporter(cfg *config.Config, stats *stats.SendStats) (*DryRunExporter, error) {
+	dryRunCfg := *cfg
+	if cfg.Protocol == config.PROTOCOL_GRPC {
+		dryRunCfg.OTLPEncoding = config.ENCODING_PROTOBUF
+		dryRunCfg.Compression = config.COMPRESSION_NONE
+	}
+	hs, err := sender.NewHTTPSenderWithClient(&dryRunCfg, &http.Client{Transport: acceptAll{}})
+	if err != nil {
+		return nil, err
+	}
+	return &DryRunExporter{sender: hs, stats: stats, grpc: cfg.Protocol == config.PROTOCOL_GRPC}, nil
+}
+
+// Export records the requests the job would be sent as. gRPC endpoints, which may be
+// given as host:port, are turned into URLs for the HTTP sender that measures them.
+func (e *DryRunExporter) Export(ctx context.Context, job structs.TelemetryJob) error {
+	destination := e.stats.Destination(job.Endpoint)
+	if e.grpc {
+		target, _ := sender.GRPCTarget(job.Endpoint)
+		job.Endpoint = "http://" + target
+	}
+	return e.sender.SendJob(ctx, job, destination)
+}
+
+// Shutdown does nothing, no connection was opened
+func (e *DryRunExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// acceptAll answers every request with an empty 200 response without sending it
+type acceptAll struct{}
+
+func (acceptAll) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
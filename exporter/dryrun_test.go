@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+func TestDryRunExporter(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	cfg := config.NewConfig()
+	cfg.DryRun = true
+	cfg.MaxRequestBytes = 85
+	st := &stats.SendStats{}
+	exp, err := New(cfg, st)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if _, ok := exp.(*DryRunExporter); !ok {
+		t.Fatalf("Expected a DryRunExporter, got %T", exp)
+	}
+	defer exp.Shutdown(context.Background())
+
+	spans := []any{map[string]any{"name": "first-operation"}, map[string]any{"name": "second-operation"}}
+	job := structs.TelemetryJob{
+		Endpoint:      mock.TracesURL(),
+		Payload:       map[string]any{"resourceSpans": []any{map[string]any{"scopeSpans": []any{map[string]any{"spans": spans}}}}},
+		TelemetryType: structs.TelemetryTraces,
+	}
+	if err := exp.Export(context.Background(), job); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	if _, _, _, total := mock.GetStats(); total != 0 {
+		t.Errorf("Expected no request to reach the collector, got %d", total)
+	}
+	destination := st.Destination(mock.TracesURL())
+	if destination.TracesSuccess != 2 || destination.TracesSpans != 2 || destination.TracesBytes == 0 {
+		t.Errorf("Expected the split into 2 requests to be counted, got requests=%d spans=%d bytes=%d", destination.TracesSuccess, destination.TracesSpans, destination.TracesBytes)
+	}
+}
+
+func TestDryRunExporterGRPC(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.DryRun = true
+	cfg.Protocol = config.PROTOCOL_GRPC
+	cfg.Compression = config.COMPRESSION_GZIP
+	st := &stats.SendStats{}
+	exp, err := NewDryRunExporter(cfg, st)
+	if err != nil {
+		t.Fatalf("NewDryRunExporter returned error: %v", err)
+	}
+	endpoints := []string{config.DEFAULT_OTEL_GRPC_ENDPOINT, "127.0.0.1:4317", "https://collector:443"}
+	for _, endpoint := range endpoints {
+		job := structs.TelemetryJob{Endpoint: endpoint, Payload: map[string]any{"resourceLogs": []any{}}, TelemetryType: structs.TelemetryLogs}
+		if err := exp.Export(context.Background(), job); err != nil {
+			t.Fatalf("Export to %s returned error: %v", endpoint, err)
+		}
+		if destination := st.Destination(endpoint); destination.LogsSuccess != 1 || destination.LogsFailed != 0 {
+			t.Errorf("Expected 1 request counted for %s, got requests=%d failed=%d", endpoint, destination.LogsSuccess, destination.LogsFailed)
+		}
+	}
+	if st.LogsSuccess != len(endpoints) || st.LogsBytes != 0 {
+		t.Errorf("Expected %d requests of an empty protobuf message, got requests=%d bytes=%d", len(endpoints), st.LogsSuccess, st.LogsBytes)
+	}
+}
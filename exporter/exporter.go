@@ -0,0 +1,36 @@
+package exporter
+
+import (
+	"context"
+	"os"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// Exporter delivers telemetry jobs to a destination
+type Exporter interface {
+	// Export delivers a single job. Cancelling ctx aborts the delivery.
+	Export(ctx context.Context, job structs.TelemetryJob) error
+	// Shutdown flushes anything buffered and releases the resources of the exporter
+	Shutdown(ctx context.Context) error
+}
+
+// New creates the Exporter selected by cfg.Exporter, or a DryRunExporter when cfg.DryRun
+// is set. Exporters record their counts on the stats of the destination of every job.
+func New(cfg *config.Config, stats *stats.SendStats) (Exporter, error) {
+	if cfg.DryRun {
+		return NewDryRunExporter(cfg, stats)
+	}
+	switch cfg.Exporter {
+	case "", config.EXPORTER_OTLP:
+		return NewOTLPExporter(cfg, stats)
+	case config.EXPORTER_FILE:
+		return NewFileExporter(cfg.ExportFile, stats)
+	case config.EXPORTER_STDOUT:
+		return NewStdoutExporter(os.Stdout, stats), nil
+	default:
+		return nil, &config.InvalidOptionError{Option: "exporter", Value: cfg.Exporter}
+	}
+}
@@ -0,0 +1,44 @@
+package exporter
+
+import "fmt"
+
+// FileOpenError represents an error when the export file cannot be opened
+type FileOpenError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileOpenError) Error() string {
+	return fmt.Sprintf("failed to open export file %s: %v", e.Path, e.Err)
+}
+
+func (e *FileOpenError) Unwrap() error {
+	return e.Err
+}
+
+// WriteError represents an error when an exported payload cannot be written
+type WriteError struct {
+	Path string
+	Err  error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("failed to write export to %s: %v", e.Path, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// EncodeError represents an error when a payload cannot be encoded as JSON
+type EncodeError struct {
+	Err error
+}
+
+func (e *EncodeError) Error() string {
+	return fmt.Sprintf("failed to encode payload: %v", e.Err)
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
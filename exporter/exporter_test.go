@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+func traceJob(endpoint string, lineNum int) structs.TelemetryJob {
+	return structs.TelemetryJob{
+		Endpoint:      endpoint,
+		Payload:       map[string]any{"resourceSpans": []any{map[string]any{"scopeSpans": []any{map[string]any{"spans": []any{map[string]any{"name": "op"}}}}}}},
+		TelemetryType: structs.TelemetryTraces,
+		LineNum:       lineNum,
+	}
+}
+
+func TestNew(t *testing.T) {
+	st := &stats.SendStats{}
+	exp, err := New(&config.Config{}, st)
+	if _, ok := exp.(*OTLPExporter); !ok || err != nil {
+		t.Errorf("Expected the OTLP exporter by default, got %T: %v", exp, err)
+	}
+	exp.Shutdown(context.Background())
+	exp, err = New(&config.Config{Exporter: config.EXPORTER_STDOUT}, st)
+	if _, ok := exp.(*StdoutExporter); !ok || err != nil {
+		t.Errorf("Expected the stdout exporter, got %T: %v", exp, err)
+	}
+	exp, err = New(&config.Config{Exporter: config.EXPORTER_FILE, ExportFile: t.TempDir() + "/out.jsonl"}, st)
+	if _, ok := exp.(*FileExporter); !ok || err != nil {
+		t.Errorf("Expected the file exporter, got %T: %v", exp, err)
+	}
+	exp.Shutdown(context.Background())
+	_, err = New(&config.Config{Exporter: "kafka"}, st)
+	var optionErr *config.InvalidOptionError
+	if !errors.As(err, &optionErr) || optionErr.Option != "exporter" {
+		t.Errorf("Expected InvalidOptionError for an unknown exporter, got %T: %v", err, err)
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	first := testutil.NewMockOTelCollector()
+	defer first.Close()
+	second := testutil.NewMockOTelCollector()
+	defer second.Close()
+	second.ShouldFail = true
+
+	st := &stats.SendStats{}
+	exp, err := NewOTLPExporter(&config.Config{}, st)
+	if err != nil {
+		t.Fatalf("NewOTLPExporter returned error: %v", err)
+	}
+	defer exp.Shutdown(context.Background())
+	if err := exp.Export(context.Background(), traceJob(first.TracesURL(), 1)); err != nil {
+		t.Errorf("Export returned error: %v", err)
+	}
+	if err := exp.Export(context.Background(), traceJob(second.TracesURL(), 1)); err == nil {
+		t.Error("Expected Export to a failing collector to return an error")
+	}
+	if traces, _, _, _ := first.GetStats(); traces != 1 {
+		t.Errorf("Expected the collector to receive 1 trace request, got %d", traces)
+	}
+	if st.Destination(first.TracesURL()).TracesSuccess != 1 || st.Destination(second.TracesURL()).TracesFailed != 1 {
+		t.Error("Expected the counts to be recorded per destination")
+	}
+	if st.TracesSuccess != 1 || st.TracesFailed != 1 {
+		t.Errorf("Expected totals of 1 success and 1 failure, got success=%d failed=%d", st.TracesSuccess, st.TracesFailed)
+	}
+}
@@ -0,0 +1,66 @@
+package exporter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/laiambryant/telemetry-ingestor/otlp"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// FileExporter appends the payload of every job to a JSON Lines file in OTLP/JSON,
+// the same format the ingestor reads, so exported files can be ingested again
+type FileExporter struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	writer *bufio.Writer
+	stats  *stats.SendStats
+}
+
+// NewFileExporter opens path for appending, creating it if needed
+func NewFileExporter(path string, stats *stats.SendStats) (*FileExporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, &FileOpenError{Path: path, Err: err}
+	}
+	return &FileExporter{path: path, file: file, writer: bufio.NewWriter(file), stats: stats}, nil
+}
+
+// Export writes the job payload as a single line
+func (e *FileExporter) Export(ctx context.Context, job structs.TelemetryJob) error {
+	data, err := json.Marshal(job.Payload)
+	if err != nil {
+		e.stats.Destination(job.Endpoint).RecordFailure(job.TelemetryType)
+		return &EncodeError{Err: err}
+	}
+	data = append(data, '\n')
+
+	e.mu.Lock()
+	_, err = e.writer.Write(data)
+	e.mu.Unlock()
+
+	destination := e.stats.Destination(job.Endpoint)
+	if err != nil {
+		destination.RecordFailure(job.TelemetryType)
+		return &WriteError{Path: e.path, Err: err}
+	}
+	destination.RecordDispatched(job.TelemetryType, otlp.CountItems(job.Payload, job.TelemetryType), int64(len(data)))
+	destination.RecordSuccess(job.TelemetryType)
+	return nil
+}
+
+// Shutdown flushes buffered lines and closes the file
+func (e *FileExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.writer.Flush(); err != nil {
+		e.file.Close()
+		return &WriteError{Path: e.path, Err: err}
+	}
+	return e.file.Close()
+}
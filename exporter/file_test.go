@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.jsonl")
+	st := &stats.SendStats{}
+	exp, err := NewFileExporter(path, st)
+	if err != nil {
+		t.Fatalf("NewFileExporter returned error: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			exp.Export(context.Background(), traceJob(path, i))
+		}()
+	}
+	wg.Wait()
+	logs := structs.TelemetryJob{Endpoint: path, Payload: map[string]any{"resourceLogs": []any{}}, TelemetryType: structs.TelemetryLogs}
+	if err := exp.Export(context.Background(), logs); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	if err := exp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read export file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 21 {
+		t.Fatalf("Expected 21 lines, got %d", len(lines))
+	}
+	if lines[20] != `{"resourceLogs":[]}` {
+		t.Errorf("Expected the payload in OTLP/JSON, got %s", lines[20])
+	}
+	if st.TracesSuccess != 20 || st.TracesSpans != 20 || st.LogsSuccess != 1 {
+		t.Errorf("Unexpected counts: traces=%d spans=%d logs=%d", st.TracesSuccess, st.TracesSpans, st.LogsSuccess)
+	}
+}
+
+func TestFileExporterOpenError(t *testing.T) {
+	_, err := NewFileExporter(filepath.Join(t.TempDir(), "missing", "out.jsonl"), &stats.SendStats{})
+	var openErr *FileOpenError
+	if !errors.As(err, &openErr) || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected FileOpenError wrapping os.ErrNotExist, got %T: %v", err, err)
+	}
+}
+
+func TestFileExporterEncodeError(t *testing.T) {
+	exp, err := NewFileExporter(filepath.Join(t.TempDir(), "out.jsonl"), &stats.SendStats{})
+	if err != nil {
+		t.Fatalf("NewFileExporter returned error: %v", err)
+	}
+	defer exp.Shutdown(context.Background())
+	job := structs.TelemetryJob{Payload: map[string]any{"resourceSpans": make(chan int)}, TelemetryType: structs.TelemetryTraces}
+	var encodeErr *EncodeError
+	if err := exp.Export(context.Background(), job); !errors.As(err, &encodeErr) {
+		t.Errorf("Expected EncodeError, got %T: %v", err, err)
+	}
+}
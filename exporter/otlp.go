@@ -0,0 +1,35 @@
+package exporter
+
+import (
+	"context"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/sender"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// OTLPExporter sends jobs to OpenTelemetry collectors over OTLP/HTTP or OTLP/gRPC
+type OTLPExporter struct {
+	transport sender.Transport
+	stats     *stats.SendStats
+}
+
+// NewOTLPExporter creates an OTLPExporter using the transport selected by cfg.Protocol
+func NewOTLPExporter(cfg *config.Config, stats *stats.SendStats) (*OTLPExporter, error) {
+	transport, err := sender.NewTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &OTLPExporter{transport: transport, stats: stats}, nil
+}
+
+// Export sends the job to its endpoint, retrying retryable failures
+func (e *OTLPExporter) Export(ctx context.Context, job structs.TelemetryJob) error {
+	return e.transport.SendJob(ctx, job, e.stats.Destination(job.Endpoint))
+}
+
+// Shutdown closes the connections of the transport
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	return e.transport.Close()
+}
@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/laiambryant/telemetry-ingestor/otlp"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// StdoutExporter pretty-prints the payload of every job, preceded by a comment line
+// naming its telemetry type and source line
+type StdoutExporter struct {
+	mu    sync.Mutex
+	out   io.Writer
+	stats *stats.SendStats
+}
+
+// NewStdoutExporter creates a StdoutExporter writing to out
+func NewStdoutExporter(out io.Writer, stats *stats.SendStats) *StdoutExporter {
+	return &StdoutExporter{out: out, stats: stats}
+}
+
+// Export writes the job payload as indented JSON
+func (e *StdoutExporter) Export(ctx context.Context, job structs.TelemetryJob) error {
+	destination := e.stats.Destination(job.Endpoint)
+	data, err := json.MarshalIndent(job.Payload, "", "  ")
+	if err != nil {
+		destination.RecordFailure(job.TelemetryType)
+		return &EncodeError{Err: err}
+	}
+
+	e.mu.Lock()
+	_, err = fmt.Fprintf(e.out, "# %s from line %d\n%s\n", job.TelemetryType, job.LineNum, data)
+	e.mu.Unlock()
+
+	if err != nil {
+		destination.RecordFailure(job.TelemetryType)
+		return &WriteError{Path: "stdout", Err: err}
+	}
+	destination.RecordDispatched(job.TelemetryType, otlp.CountItems(job.Payload, job.TelemetryType), int64(len(data)))
+	destination.RecordSuccess(job.TelemetryType)
+	return nil
+}
+
+// Shutdown does nothing, the output is written unbuffered
+func (e *StdoutExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
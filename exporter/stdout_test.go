@@ -0,0 +1,44 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/laiambryant/telemetry-ingestor/stats"
+)
+
+func TestStdoutExporter(t *testing.T) {
+	var buf bytes.Buffer
+	st := &stats.SendStats{}
+	exp := NewStdoutExporter(&buf, st)
+	if err := exp.Export(context.Background(), traceJob("stdout", 3)); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	if err := exp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+	expected := `# Traces from line 3
+{
+  "resourceSpans": [
+    {
+      "scopeSpans": [
+        {
+          "spans": [
+            {
+              "name": "op"
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s", buf.String())
+	}
+	if st.TracesSuccess != 1 || st.TracesSpans != 1 {
+		t.Errorf("Expected 1 success with 1 span, got success=%d spans=%d", st.TracesSuccess, st.TracesSpans)
+	}
+}
@@ -2,7 +2,23 @@ module github.com/laiambryant/telemetry-ingestor
 
 go 1.23.0
 
-require github.com/spf13/cobra v1.10.1
+require (
+	github.com/klauspost/compress v1.18.0
+	github.com/spf13/cobra v1.10.1
+	github.com/ulikunitz/xz v0.5.15
+	go.opentelemetry.io/proto/otlp v1.7.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250528174236-200df99c418a
+	google.golang.org/grpc v1.72.2
+	google.golang.org/protobuf v1.36.6
+)
+
+require (
+	github.com/grpc-ecosystem/grpc-gateway/v2 v2.26.3 // indirect
+	golang.org/x/net v0.40.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.25.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20250528174236-200df99c418a // indirect
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
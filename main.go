@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/laiambryant/telemetry-ingestor/config"
 	"github.com/laiambryant/telemetry-ingestor/processor"
@@ -12,35 +16,143 @@ import (
 var cfg = config.NewConfig()
 
 var rootCmd = &cobra.Command{
-	Use:   "ingest_telemetry [file]",
+	Use:   "ingest_telemetry [file|dir|glob...]",
 	Short: "Ingest telemetry data to OpenTelemetry Collector",
 	Long: `Reads OTLP format telemetry data from JSON Lines files and sends it to an OpenTelemetry Collector.
-Finds and sends the last instance of each telemetry type (traces, logs, metrics).`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: runIngest,
+Finds and sends the last instance of each telemetry type (traces, logs, metrics).
+Several files, directories and glob patterns may be given; they are ingested one after the other.
+Use - as the file to read from standard input, which is also read when it is piped and no file is given.`,
+	Args:              cobra.ArbitraryArgs,
+	PersistentPreRunE: prepare,
+	RunE:              runIngest,
+}
+
+var replayDeadLetterCmd = &cobra.Command{
+	Use:   "replay-dead-letter <file>",
+	Short: "Send the payloads of a dead-letter file again",
+	Long: `Feeds a dead-letter file written with --dead-letter back through the send-all pipeline,
+using the options given on the command line. Each payload goes back to the endpoint it failed
+on, unless --configured-endpoints sends it to the endpoints given on the command line instead.
+Payloads that fail again are written to the --dead-letter file, if one is set, which must differ
+from the file being replayed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReplayDeadLetter,
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&cfg.FilePath, "file", "f", "telemetry.json", "Path to telemetry JSON file")
-	rootCmd.Flags().StringVar(&cfg.OtelEndpoint, "traces-endpoint", config.DEFAULT_OTEL_ENDPOINT, "OpenTelemetry traces endpoint")
-	rootCmd.Flags().StringVar(&cfg.OtelLogsEndpoint, "logs-endpoint", config.DEFAULT_OTEL_LOGS_ENDPOINT, "OpenTelemetry logs endpoint")
-	rootCmd.Flags().StringVar(&cfg.OtelMetricsEndpoint, "metrics-endpoint", config.DEFAULT_OTEL_METRICS_ENDPOINT, "OpenTelemetry metrics endpoint")
-	rootCmd.Flags().IntVar(&cfg.MaxBufferCapacity, "max-buffer-capacity", 1024*1024, "Maximum buffer capacity in bytes for reading lines (default 1MB)")
-	rootCmd.Flags().BoolVar(&cfg.SendAll, "sendAll", false, "Send all telemetry lines instead of only the last instance of each type")
-	rootCmd.Flags().IntVar(&cfg.Workers, "workers", 10, "Number of concurrent workers for sending telemetry (only used with --sendAll)")
+	rootCmd.Flags().StringVarP(&cfg.FilePath, "file", "f", "telemetry.json", "Path to telemetry JSON file, - reads standard input")
+	rootCmd.Flags().BoolVarP(&cfg.Recursive, "recursive", "r", false, "Also ingest the files in subdirectories of directory arguments")
+	rootCmd.Flags().BoolVar(&cfg.FailFast, "fail-fast", false, "Stop at the first input that fails instead of moving on to the next one")
+	rootCmd.Flags().BoolVar(&cfg.Follow, "follow", false, "Keep reading the file as it grows, like tail -F, and send new lines until interrupted (implies --sendAll)")
+	rootCmd.Flags().DurationVar(&cfg.FollowInterval, "follow-interval", time.Second, "How often a followed file is checked for new data, truncation and rotation")
+	rootCmd.PersistentFlags().StringVar(&cfg.OtelEndpoint, "traces-endpoint", config.DEFAULT_OTEL_ENDPOINT, "OpenTelemetry traces endpoint, several destinations may be given comma-separated")
+	rootCmd.PersistentFlags().StringVar(&cfg.OtelLogsEndpoint, "logs-endpoint", config.DEFAULT_OTEL_LOGS_ENDPOINT, "OpenTelemetry logs endpoint, several destinations may be given comma-separated")
+	rootCmd.PersistentFlags().StringVar(&cfg.OtelMetricsEndpoint, "metrics-endpoint", config.DEFAULT_OTEL_METRICS_ENDPOINT, "OpenTelemetry metrics endpoint, several destinations may be given comma-separated")
+	rootCmd.PersistentFlags().StringVar(&cfg.Protocol, "protocol", config.PROTOCOL_HTTP, "OTLP transport protocol: http or grpc (grpc endpoints default to "+config.DEFAULT_OTEL_GRPC_ENDPOINT+")")
+	rootCmd.PersistentFlags().StringVar(&cfg.Exporter, "exporter", config.EXPORTER_OTLP, "Where telemetry is exported: otlp (collector endpoints), file (--export-file) or stdout")
+	rootCmd.PersistentFlags().StringVar(&cfg.ExportFile, "export-file", "", "JSON Lines file the file exporter appends payloads to")
+	rootCmd.PersistentFlags().StringVar(&cfg.OTLPEncoding, "otlp-encoding", config.ENCODING_JSON, "OTLP payload encoding: json or protobuf")
+	rootCmd.PersistentFlags().StringVar(&cfg.Compression, "compression", config.COMPRESSION_NONE, "Request compression: none, gzip or zstd (zstd is only available over http)")
+	rootCmd.PersistentFlags().StringArrayVar(&cfg.Headers, "header", nil, "Header added to every request as key=value (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&cfg.TracesHeaders, "traces-header", nil, "Header added to trace requests as key=value, overrides --header (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&cfg.LogsHeaders, "logs-header", nil, "Header added to log requests as key=value, overrides --header (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&cfg.MetricsHeaders, "metrics-header", nil, "Header added to metric requests as key=value, overrides --header (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&cfg.AuthMode, "auth", config.AUTH_NONE, "Authentication mode: none, bearer, basic or api-key")
+	rootCmd.PersistentFlags().StringVar(&cfg.AuthUsername, "auth-username", "", "Username for basic authentication")
+	rootCmd.PersistentFlags().StringVar(&cfg.AuthSecretEnv, "auth-secret-env", "", "Environment variable holding the bearer token, password or API key")
+	rootCmd.PersistentFlags().StringVar(&cfg.AuthSecretFile, "auth-secret-file", "", "File holding the bearer token, password or API key")
+	rootCmd.PersistentFlags().StringVar(&cfg.APIKeyHeader, "api-key-header", config.DEFAULT_API_KEY_HEADER, "Header carrying the API key in api-key mode")
+	rootCmd.PersistentFlags().StringVar(&cfg.TLSCAFile, "tls-ca-file", "", "PEM bundle of CA certificates used to verify the collector")
+	rootCmd.PersistentFlags().StringVar(&cfg.TLSCertFile, "tls-cert-file", "", "PEM client certificate for mutual TLS")
+	rootCmd.PersistentFlags().StringVar(&cfg.TLSKeyFile, "tls-key-file", "", "PEM client private key for mutual TLS")
+	rootCmd.PersistentFlags().StringVar(&cfg.TLSServerName, "tls-server-name", "", "Override the server name used to verify the collector certificate")
+	rootCmd.PersistentFlags().BoolVar(&cfg.InsecureSkipVerify, "insecure-skip-verify", false, "Skip verification of the collector certificate (insecure)")
+	rootCmd.PersistentFlags().IntVar(&cfg.MaxBufferCapacity, "max-buffer-capacity", 1024*1024, "Maximum buffer capacity in bytes for reading lines (default 1MB)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.SendAll, "sendAll", false, "Send all telemetry lines instead of only the last instance of each type")
+	rootCmd.PersistentFlags().IntVar(&cfg.Workers, "workers", 10, "Number of concurrent workers for sending telemetry (only used with --sendAll)")
+	rootCmd.PersistentFlags().IntVar(&cfg.QueueSize, "queue-size", 1000, "Jobs each destination may fall behind before reading waits for it (only used with --sendAll)")
+	rootCmd.PersistentFlags().IntVar(&cfg.MaxRetries, "max-retries", 3, "Maximum number of retries for retryable failures (429, 502, 503, 504, refused or reset connections and timeouts)")
+	rootCmd.PersistentFlags().DurationVar(&cfg.RetryInitialBackoff, "retry-initial-backoff", 500*time.Millisecond, "Initial backoff before the first retry")
+	rootCmd.PersistentFlags().DurationVar(&cfg.RetryMaxBackoff, "retry-max-backoff", 30*time.Second, "Maximum backoff between retries, also caps waits requested with Retry-After")
+	rootCmd.PersistentFlags().DurationVar(&cfg.ConnectTimeout, "connect-timeout", 10*time.Second, "Timeout for establishing a connection to the collector, including the TLS handshake (0 disables)")
+	rootCmd.PersistentFlags().DurationVar(&cfg.ResponseTimeout, "response-timeout", 30*time.Second, "Timeout waiting for the collector's response headers over http (0 disables)")
+	rootCmd.PersistentFlags().DurationVar(&cfg.RequestTimeout, "request-timeout", 60*time.Second, "Overall timeout for each request attempt (0 disables)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.DryRun, "dry-run", false, "Read, parse and route the input and report what would be sent without sending anything")
+	rootCmd.PersistentFlags().BoolVar(&cfg.ReplayPacing, "replay-pacing", false, "Send each line after the gap to the previous one in its original timestamps (only used with --sendAll)")
+	rootCmd.PersistentFlags().Float64Var(&cfg.ReplaySpeed, "speed", 1, "Replay speed factor for --replay-pacing, 10 replays ten times faster than recorded")
+	rootCmd.PersistentFlags().DurationVar(&cfg.ReplayMaxGap, "max-gap", 0, "Longest wait between paced lines, longer idle gaps are shortened to it (0 keeps every gap)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.RebaseTimestamps, "rebase-timestamps", false, "Shift every timestamp so the --rebase-anchor record lands at --rebase-to, keeping the spacing between records")
+	rootCmd.PersistentFlags().StringVar(&cfg.RebaseAnchor, "rebase-anchor", config.REBASE_ANCHOR_NEWEST, "Record moved to --rebase-to when rebasing: newest or oldest")
+	rootCmd.PersistentFlags().StringVar(&cfg.RebaseTo, "rebase-to", "", "RFC 3339 instant the rebased anchor record lands at (default now)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.RegenerateIDs, "regenerate-ids", false, "Replace every trace and span ID with a fresh random ID, consistently across the lines and files of the run")
+	rootCmd.PersistentFlags().StringVar(&cfg.CheckpointPath, "checkpoint", "", "State file recording how far each input has been delivered (requires --sendAll)")
+	rootCmd.PersistentFlags().DurationVar(&cfg.CheckpointInterval, "checkpoint-interval", 5*time.Second, "How often the delivery position is saved to the --checkpoint file")
+	rootCmd.PersistentFlags().BoolVar(&cfg.Resume, "resume", false, "Skip the data the --checkpoint file records as delivered")
+	rootCmd.PersistentFlags().StringVar(&cfg.DeadLetterPath, "dead-letter", "", "Append payloads that could not be delivered to this JSON Lines file")
+	rootCmd.PersistentFlags().IntVar(&cfg.MaxRequestBytes, "max-request-bytes", 0, "Split payloads whose encoded size exceeds this many bytes into several requests (0 disables splitting)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.Batch, "batch", false, "Merge consecutive lines into batched export requests (only used with --sendAll)")
+	rootCmd.PersistentFlags().IntVar(&cfg.BatchMaxItems, "batch-max-items", 512, "Maximum spans, log records or data points per batch (0 disables the limit)")
+	rootCmd.PersistentFlags().IntVar(&cfg.BatchMaxBytes, "batch-max-bytes", 1024*1024, "Maximum JSON size in bytes of the resources in a batch (0 disables the limit)")
+	rootCmd.PersistentFlags().DurationVar(&cfg.BatchFlushInterval, "batch-flush-interval", time.Second, "Maximum time a line waits in a batch before it is sent (0 disables the timer)")
+	rootCmd.PersistentFlags().IntVar(&cfg.BreakerThreshold, "breaker-threshold", 5, "Consecutive failures that open the circuit breaker of an endpoint (0 disables the breaker)")
+	rootCmd.PersistentFlags().DurationVar(&cfg.BreakerCooldown, "breaker-cooldown", 30*time.Second, "Time an open circuit breaker fails requests fast before probing the endpoint again")
+	rootCmd.PersistentFlags().DurationVar(&cfg.ShutdownGracePeriod, "shutdown-grace-period", 10*time.Second, "Time allowed for queued and in-flight telemetry to drain after SIGINT or SIGTERM")
+
+	replayDeadLetterCmd.Flags().BoolVar(&cfg.ConfiguredEndpoints, "configured-endpoints", false, "Send every payload to the configured endpoints of its signal instead of the endpoint it failed on")
+
+	rootCmd.AddCommand(replayDeadLetterCmd)
 }
 
 func main() {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
-	if err := rootCmd.Execute(); err != nil {
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Restore the default handlers after the first signal so a second one exits immediately
+	stopAfter := context.AfterFunc(ctx, func() {
+		slog.Warn("Received shutdown signal, send it again to exit immediately")
+		stop()
+	})
+	defer stopAfter()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		os.Exit(1)
 	}
 }
 
+// prepare applies the defaults that depend on other flags before any command runs
+func prepare(cmd *cobra.Command, args []string) error {
+	if cfg.Protocol == config.PROTOCOL_GRPC {
+		cfg.UseGRPCDefaults(cmd.Flags().Changed)
+	}
+	// Keep stdout for the exported telemetry
+	if cfg.Exporter == config.EXPORTER_STDOUT {
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	}
+	return nil
+}
+
 func runIngest(cmd *cobra.Command, args []string) error {
-	if len(args) > 0 {
-		cfg.FilePath = args[0]
+	if len(args) == 0 {
+		if !cmd.Flags().Changed("file") && stdinPiped() {
+			cfg.FilePath = processor.StdinPath
+		}
+		args = []string{cfg.FilePath}
 	}
-	return processor.IngestTelemetry(cfg.FilePath, cfg)
+	inputs, err := processor.ExpandInputs(args, cfg.Recursive)
+	if err != nil {
+		return err
+	}
+	return processor.IngestTelemetryFiles(cmd.Context(), inputs, cfg)
+}
+
+func runReplayDeadLetter(cmd *cobra.Command, args []string) error {
+	return processor.ReplayDeadLetter(cmd.Context(), args[0], cfg)
+}
+
+// stdinPiped reports whether standard input is a pipe or a redirected file rather than a terminal
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
 }
@@ -0,0 +1,116 @@
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+	collogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// idFields are the OTLP/JSON fields that carry hex encoded identifiers,
+// while the canonical protobuf JSON mapping expects base64 for bytes fields
+var idFields = map[string]bool{
+	"traceId":      true,
+	"spanId":       true,
+	"parentSpanId": true,
+}
+
+// NewExportRequest returns an empty Export*ServiceRequest for the given telemetry type
+func NewExportRequest(telemetryType s.TelemetryType) (proto.Message, error) {
+	switch telemetryType {
+	case s.TelemetryTraces:
+		return &coltrace.ExportTraceServiceRequest{}, nil
+	case s.TelemetryLogs:
+		return &collogs.ExportLogsServiceRequest{}, nil
+	case s.TelemetryMetrics:
+		return &colmetrics.ExportMetricsServiceRequest{}, nil
+	default:
+		return nil, &UnknownTelemetryTypeError{TelemetryType: telemetryType}
+	}
+}
+
+// ToProto converts an OTLP/JSON payload into the Export*ServiceRequest matching the telemetry type
+func ToProto(payload any, telemetryType s.TelemetryType) (proto.Message, error) {
+	msg, err := NewExportRequest(telemetryType)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, err := json.Marshal(convertIDs(payload, hexToBase64))
+	if err != nil {
+		return nil, &ConversionError{TelemetryType: telemetryType, Err: err}
+	}
+
+	opts := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err := opts.Unmarshal(jsonData, msg); err != nil {
+		return nil, &ConversionError{TelemetryType: telemetryType, Err: err}
+	}
+
+	return msg, nil
+}
+
+// FromProto converts an Export*ServiceRequest back into an OTLP/JSON payload
+func FromProto(msg proto.Message) (map[string]any, error) {
+	opts := protojson.MarshalOptions{UseEnumNumbers: true}
+	jsonData, err := opts.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(jsonData, &data); err != nil {
+		return nil, err
+	}
+
+	converted, _ := convertIDs(data, base64ToHex).(map[string]any)
+	return converted, nil
+}
+
+// convertIDs returns a copy of value with every identifier field rewritten by convert.
+// The input is never modified because payloads are shared between jobs.
+func convertIDs(value any, convert func(string) string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, field := range v {
+			if id, ok := field.(string); ok && idFields[key] {
+				out[key] = convert(id)
+				continue
+			}
+			out[key] = convertIDs(field, convert)
+		}
+		return out
+	case s.TelemetryData:
+		return convertIDs(map[string]any(v), convert)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = convertIDs(item, convert)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+func hexToBase64(id string) string {
+	raw, err := hex.DecodeString(id)
+	if err != nil {
+		return id
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func base64ToHex(id string) string {
+	raw, err := base64.StdEncoding.DecodeString(id)
+	if err != nil {
+		return id
+	}
+	return hex.EncodeToString(raw)
+}
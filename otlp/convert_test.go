@@ -0,0 +1,118 @@
+package otlp
+
+import (
+	"errors"
+	"testing"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+	coltrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+)
+
+var tracePayload = map[string]any{
+	"resourceSpans": []any{
+		map[string]any{
+			"resource": map[string]any{
+				"attributes": []any{
+					map[string]any{"key": "service.name", "value": map[string]any{"stringValue": "test-service"}},
+				},
+			},
+			"scopeSpans": []any{
+				map[string]any{
+					"spans": []any{
+						map[string]any{
+							"traceId":           "5b8efff798038103d269b633813fc60c",
+							"spanId":            "eee19b7ec3c1b174",
+							"parentSpanId":      "eee19b7ec3c1b173",
+							"name":              "test-span",
+							"kind":              2,
+							"startTimeUnixNano": "1544712660000000000",
+							"endTimeUnixNano":   "1544712661000000000",
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestToProtoTraces(t *testing.T) {
+	msg, err := ToProto(tracePayload, s.TelemetryTraces)
+	if err != nil {
+		t.Fatalf("ToProto returned error: %v", err)
+	}
+	req, ok := msg.(*coltrace.ExportTraceServiceRequest)
+	if !ok {
+		t.Fatalf("Expected ExportTraceServiceRequest, got %T", msg)
+	}
+	span := req.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if len(span.TraceId) != 16 || len(span.SpanId) != 8 || len(span.ParentSpanId) != 8 {
+		t.Errorf("Expected hex IDs to decode to raw bytes, got trace=%d span=%d parent=%d bytes",
+			len(span.TraceId), len(span.SpanId), len(span.ParentSpanId))
+	}
+	if span.StartTimeUnixNano != 1544712660000000000 {
+		t.Errorf("Expected start time to be preserved, got %d", span.StartTimeUnixNano)
+	}
+	if span.Name != "test-span" {
+		t.Errorf("Expected span name 'test-span', got '%s'", span.Name)
+	}
+}
+
+func TestToProtoDoesNotModifyPayload(t *testing.T) {
+	if _, err := ToProto(tracePayload, s.TelemetryTraces); err != nil {
+		t.Fatalf("ToProto returned error: %v", err)
+	}
+	span := tracePayload["resourceSpans"].([]any)[0].(map[string]any)["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	if span["traceId"] != "5b8efff798038103d269b633813fc60c" {
+		t.Errorf("Expected payload traceId to be untouched, got %v", span["traceId"])
+	}
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	msg, err := ToProto(tracePayload, s.TelemetryTraces)
+	if err != nil {
+		t.Fatalf("ToProto returned error: %v", err)
+	}
+	data, err := FromProto(msg)
+	if err != nil {
+		t.Fatalf("FromProto returned error: %v", err)
+	}
+	span := data["resourceSpans"].([]any)[0].(map[string]any)["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	if span["traceId"] != "5b8efff798038103d269b633813fc60c" {
+		t.Errorf("Expected traceId to round trip as hex, got %v", span["traceId"])
+	}
+	if span["kind"] != float64(2) {
+		t.Errorf("Expected kind to round trip as a number, got %v", span["kind"])
+	}
+}
+
+func TestToProtoAllTypes(t *testing.T) {
+	test1 := c.NewCharacterizationTest(true, nil, func() (bool, error) {
+		_, err := ToProto(map[string]any{"resourceLogs": []any{map[string]any{"scopeLogs": []any{}}}}, s.TelemetryLogs)
+		return err == nil, nil
+	})
+	test2 := c.NewCharacterizationTest(true, nil, func() (bool, error) {
+		_, err := ToProto(map[string]any{"resourceMetrics": []any{map[string]any{"scopeMetrics": []any{}}}}, s.TelemetryMetrics)
+		return err == nil, nil
+	})
+	test3 := c.NewCharacterizationTest(true, nil, func() (bool, error) {
+		_, err := ToProto(map[string]any{"resourceSpans": []any{map[string]any{"id": 1}}}, s.TelemetryTraces)
+		return err == nil, nil
+	})
+	tests := []c.CharacterizationTest[bool]{test1, test2, test3}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestToProtoErrors(t *testing.T) {
+	_, err := ToProto(map[string]any{}, s.TelemetryType(999))
+	var typeErr *UnknownTelemetryTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("Expected UnknownTelemetryTypeError, got %T: %v", err, err)
+	}
+
+	_, err = ToProto(map[string]any{"resourceSpans": "not-a-list"}, s.TelemetryTraces)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Errorf("Expected ConversionError, got %T: %v", err, err)
+	}
+}
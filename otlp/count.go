@@ -0,0 +1,68 @@
+package otlp
+
+import (
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// metricDataFields are the metric fields whose value holds a dataPoints array
+var metricDataFields = []string{"gauge", "sum", "histogram", "exponentialHistogram", "summary"}
+
+// CountItems returns the number of spans, log records or metric data points in an OTLP/JSON payload
+func CountItems(payload any, telemetryType s.TelemetryType) int {
+	switch telemetryType {
+	case s.TelemetryTraces:
+		return countNested(payload, "resourceSpans", "scopeSpans", "spans")
+	case s.TelemetryLogs:
+		return countNested(payload, "resourceLogs", "scopeLogs", "logRecords")
+	case s.TelemetryMetrics:
+		count := 0
+		for _, resource := range field(payload, "resourceMetrics") {
+			for _, scope := range field(resource, "scopeMetrics") {
+				for _, metric := range field(scope, "metrics") {
+					count += countDataPoints(metric)
+				}
+			}
+		}
+		return count
+	default:
+		return 0
+	}
+}
+
+// countNested counts the items of the innermost array in a resource/scope/item hierarchy
+func countNested(payload any, resourceKey, scopeKey, itemKey string) int {
+	count := 0
+	for _, resource := range field(payload, resourceKey) {
+		for _, scope := range field(resource, scopeKey) {
+			count += len(field(scope, itemKey))
+		}
+	}
+	return count
+}
+
+func countDataPoints(metric any) int {
+	m := asMap(metric)
+	for _, key := range metricDataFields {
+		if data, ok := m[key]; ok {
+			return len(field(data, "dataPoints"))
+		}
+	}
+	return 0
+}
+
+// field returns the array stored under key, or nil when value is not an object or key is not an array
+func field(value any, key string) []any {
+	items, _ := asMap(value)[key].([]any)
+	return items
+}
+
+func asMap(value any) map[string]any {
+	switch v := value.(type) {
+	case map[string]any:
+		return v
+	case s.TelemetryData:
+		return v
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+package otlp
+
+import (
+	"testing"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+var logsPayload = s.TelemetryData{
+	"resourceLogs": []any{
+		map[string]any{"scopeLogs": []any{
+			map[string]any{"logRecords": []any{map[string]any{}, map[string]any{}}},
+			map[string]any{"logRecords": []any{map[string]any{}}},
+		}},
+		map[string]any{"scopeLogs": []any{}},
+	},
+}
+
+var metricsPayload = map[string]any{
+	"resourceMetrics": []any{
+		map[string]any{"scopeMetrics": []any{
+			map[string]any{"metrics": []any{
+				map[string]any{"name": "gauge", "gauge": map[string]any{"dataPoints": []any{map[string]any{}, map[string]any{}}}},
+				map[string]any{"name": "sum", "sum": map[string]any{"dataPoints": []any{map[string]any{}}}},
+				map[string]any{"name": "histogram", "histogram": map[string]any{"dataPoints": []any{map[string]any{}}}},
+				map[string]any{"name": "exponential", "exponentialHistogram": map[string]any{"dataPoints": []any{map[string]any{}}}},
+				map[string]any{"name": "summary", "summary": map[string]any{"dataPoints": []any{map[string]any{}, map[string]any{}, map[string]any{}}}},
+				map[string]any{"name": "empty"},
+			}},
+		}},
+	},
+}
+
+func TestCountItems(t *testing.T) {
+	newTest := func(payload any, telemetryType s.TelemetryType, expected int) c.CharacterizationTest[int] {
+		return c.NewCharacterizationTest(expected, nil, func() (int, error) {
+			return CountItems(payload, telemetryType), nil
+		})
+	}
+	tests := []c.CharacterizationTest[int]{
+		newTest(tracePayload, s.TelemetryTraces, 1),
+		newTest(logsPayload, s.TelemetryLogs, 3),
+		newTest(metricsPayload, s.TelemetryMetrics, 8),
+		newTest(map[string]any{"resourceSpans": []any{}}, s.TelemetryTraces, 0),
+		newTest(map[string]any{"resourceSpans": "invalid"}, s.TelemetryTraces, 0),
+		newTest(tracePayload, s.TelemetryLogs, 0),
+		newTest(nil, s.TelemetryMetrics, 0),
+		newTest(tracePayload, s.TelemetryType(99), 0),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
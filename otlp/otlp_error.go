@@ -0,0 +1,43 @@
+package otlp
+
+import (
+	"fmt"
+
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// UnknownTelemetryTypeError represents a telemetry type without an OTLP export request
+type UnknownTelemetryTypeError struct {
+	TelemetryType s.TelemetryType
+}
+
+func (e *UnknownTelemetryTypeError) Error() string {
+	return fmt.Sprintf("unknown telemetry type: %d", int(e.TelemetryType))
+}
+
+// ConversionError represents an error when a payload cannot be mapped onto the OTLP protobuf schema
+type ConversionError struct {
+	TelemetryType s.TelemetryType
+	Err           error
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("failed to convert %s payload to OTLP protobuf: %v", e.TelemetryType, e.Err)
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// OversizedItemError represents a single span, log record or data point that is larger than the request size limit on its own.
+// Payload is the request holding only that item.
+type OversizedItemError struct {
+	TelemetryType s.TelemetryType
+	Size          int
+	MaxBytes      int
+	Payload       any
+}
+
+func (e *OversizedItemError) Error() string {
+	return fmt.Sprintf("%s payload cannot be split under the %d byte request limit: a request holding a single item encodes to %d bytes", e.TelemetryType, e.MaxBytes, e.Size)
+}
@@ -0,0 +1,74 @@
+package otlp
+
+import (
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+	collogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const contentTypeProtobuf = "application/x-protobuf"
+
+// PartialSuccess holds the items a collector rejected from an otherwise accepted export
+type PartialSuccess struct {
+	Rejected     int64
+	ErrorMessage string
+}
+
+// NewExportResponse returns an empty Export*ServiceResponse for the given telemetry type
+func NewExportResponse(telemetryType s.TelemetryType) (proto.Message, error) {
+	switch telemetryType {
+	case s.TelemetryTraces:
+		return &coltrace.ExportTraceServiceResponse{}, nil
+	case s.TelemetryLogs:
+		return &collogs.ExportLogsServiceResponse{}, nil
+	case s.TelemetryMetrics:
+		return &colmetrics.ExportMetricsServiceResponse{}, nil
+	default:
+		return nil, &UnknownTelemetryTypeError{TelemetryType: telemetryType}
+	}
+}
+
+// DecodeResponse decodes an OTLP/HTTP response body, encoded as protobuf or JSON
+// depending on its Content-Type, and returns its partial success
+func DecodeResponse(body []byte, contentType string, telemetryType s.TelemetryType) (PartialSuccess, error) {
+	msg, err := NewExportResponse(telemetryType)
+	if err != nil || len(body) == 0 {
+		return PartialSuccess{}, err
+	}
+
+	if contentType == contentTypeProtobuf {
+		err = proto.Unmarshal(body, msg)
+	} else {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(body, msg)
+	}
+	if err != nil {
+		return PartialSuccess{}, &ConversionError{TelemetryType: telemetryType, Err: err}
+	}
+	return ResponsePartialSuccess(msg), nil
+}
+
+// ResponsePartialSuccess extracts the rejected spans, log records or data points from an Export*ServiceResponse
+func ResponsePartialSuccess(msg proto.Message) PartialSuccess {
+	switch resp := msg.(type) {
+	case *coltrace.ExportTraceServiceResponse:
+		return PartialSuccess{
+			Rejected:     resp.GetPartialSuccess().GetRejectedSpans(),
+			ErrorMessage: resp.GetPartialSuccess().GetErrorMessage(),
+		}
+	case *collogs.ExportLogsServiceResponse:
+		return PartialSuccess{
+			Rejected:     resp.GetPartialSuccess().GetRejectedLogRecords(),
+			ErrorMessage: resp.GetPartialSuccess().GetErrorMessage(),
+		}
+	case *colmetrics.ExportMetricsServiceResponse:
+		return PartialSuccess{
+			Rejected:     resp.GetPartialSuccess().GetRejectedDataPoints(),
+			ErrorMessage: resp.GetPartialSuccess().GetErrorMessage(),
+		}
+	default:
+		return PartialSuccess{}
+	}
+}
@@ -0,0 +1,59 @@
+package otlp
+
+import (
+	"errors"
+	"testing"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+	collogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDecodeResponseJSON(t *testing.T) {
+	newTest := func(body string, telemetryType s.TelemetryType, expected PartialSuccess) c.CharacterizationTest[PartialSuccess] {
+		return c.NewCharacterizationTest(expected, nil, func() (PartialSuccess, error) {
+			return DecodeResponse([]byte(body), "application/json", telemetryType)
+		})
+	}
+	tests := []c.CharacterizationTest[PartialSuccess]{
+		newTest(`{"partialSuccess":{"rejectedSpans":"3","errorMessage":"bad spans"}}`, s.TelemetryTraces, PartialSuccess{Rejected: 3, ErrorMessage: "bad spans"}),
+		newTest(`{"partialSuccess":{"rejectedLogRecords":2}}`, s.TelemetryLogs, PartialSuccess{Rejected: 2}),
+		newTest(`{"partialSuccess":{"rejectedDataPoints":"7","errorMessage":"stale"}}`, s.TelemetryMetrics, PartialSuccess{Rejected: 7, ErrorMessage: "stale"}),
+		newTest(`{"partialSuccess":{"errorMessage":"warning only"}}`, s.TelemetryTraces, PartialSuccess{ErrorMessage: "warning only"}),
+		newTest(`{}`, s.TelemetryTraces, PartialSuccess{}),
+		newTest(``, s.TelemetryLogs, PartialSuccess{}),
+		newTest(`{"unknownField":true}`, s.TelemetryMetrics, PartialSuccess{}),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestDecodeResponseProtobuf(t *testing.T) {
+	body, err := proto.Marshal(&collogs.ExportLogsServiceResponse{
+		PartialSuccess: &collogs.ExportLogsPartialSuccess{RejectedLogRecords: 4, ErrorMessage: "too old"},
+	})
+	if err != nil {
+		t.Fatalf("proto.Marshal returned error: %v", err)
+	}
+	partial, err := DecodeResponse(body, "application/x-protobuf", s.TelemetryLogs)
+	if err != nil {
+		t.Fatalf("DecodeResponse returned error: %v", err)
+	}
+	if partial.Rejected != 4 || partial.ErrorMessage != "too old" {
+		t.Errorf("Unexpected partial success: %+v", partial)
+	}
+}
+
+func TestDecodeResponseErrors(t *testing.T) {
+	_, err := DecodeResponse([]byte("not json"), "application/json", s.TelemetryTraces)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Errorf("Expected ConversionError, got %T: %v", err, err)
+	}
+
+	_, err = DecodeResponse([]byte("{}"), "application/json", s.TelemetryType(99))
+	var typeErr *UnknownTelemetryTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("Expected UnknownTelemetryTypeError, got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,143 @@
+package otlp
+
+import (
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// SizeFunc returns the encoded size in bytes of an OTLP/JSON payload
+type SizeFunc func(payload any) (int, error)
+
+// level is one step of the resource, scope, item hierarchy of an OTLP payload
+type level struct {
+	children     func(node map[string]any) []any
+	withChildren func(node map[string]any, children []any) map[string]any
+}
+
+// SplitPayload splits an OTLP/JSON payload whose size exceeds maxBytes into several valid
+// payloads that each fit. Payloads are halved along resource boundaries first, then scope
+// boundaries, then individual spans, log records or metrics and finally metric data points.
+// A single item that cannot fit on its own is left out of the pieces and returned as an
+// OversizedItemError carrying a payload with only that item.
+func SplitPayload(payload any, telemetryType s.TelemetryType, maxBytes int, size SizeFunc) ([]any, []*OversizedItemError, error) {
+	levels, err := payloadLevels(telemetryType)
+	if err != nil {
+		return nil, nil, err
+	}
+	root := asMap(payload)
+	if root == nil {
+		return []any{payload}, nil, nil
+	}
+
+	var pieces []any
+	var oversized []*OversizedItemError
+	var split func(node map[string]any) error
+	split = func(node map[string]any) error {
+		n, err := size(node)
+		if err != nil {
+			return err
+		}
+		if n <= maxBytes {
+			pieces = append(pieces, node)
+			return nil
+		}
+		left, right, ok := halve(node, levels)
+		if !ok {
+			oversized = append(oversized, &OversizedItemError{TelemetryType: telemetryType, Size: n, MaxBytes: maxBytes, Payload: node})
+			return nil
+		}
+		if err := split(left); err != nil {
+			return err
+		}
+		return split(right)
+	}
+	if err := split(root); err != nil {
+		return nil, nil, err
+	}
+	return pieces, oversized, nil
+}
+
+// halve splits the first array in the hierarchy holding more than one element, following
+// single element arrays down. It returns false when every array holds at most one element.
+func halve(node map[string]any, levels []level) (left, right map[string]any, ok bool) {
+	if len(levels) == 0 {
+		return nil, nil, false
+	}
+	children := levels[0].children(node)
+	switch {
+	case len(children) > 1:
+		mid := len(children) / 2
+		return levels[0].withChildren(node, children[:mid]), levels[0].withChildren(node, children[mid:]), true
+	case len(children) == 1:
+		child := asMap(children[0])
+		if child == nil {
+			return nil, nil, false
+		}
+		l, r, ok := halve(child, levels[1:])
+		if !ok {
+			return nil, nil, false
+		}
+		return levels[0].withChildren(node, []any{l}), levels[0].withChildren(node, []any{r}), true
+	default:
+		return nil, nil, false
+	}
+}
+
+func payloadLevels(telemetryType s.TelemetryType) ([]level, error) {
+	switch telemetryType {
+	case s.TelemetryTraces:
+		return []level{arrayLevel("resourceSpans"), arrayLevel("scopeSpans"), arrayLevel("spans")}, nil
+	case s.TelemetryLogs:
+		return []level{arrayLevel("resourceLogs"), arrayLevel("scopeLogs"), arrayLevel("logRecords")}, nil
+	case s.TelemetryMetrics:
+		return []level{arrayLevel("resourceMetrics"), arrayLevel("scopeMetrics"), arrayLevel("metrics"), dataPointsLevel()}, nil
+	default:
+		return nil, &UnknownTelemetryTypeError{TelemetryType: telemetryType}
+	}
+}
+
+// arrayLevel is a level whose children are stored directly under key
+func arrayLevel(key string) level {
+	return level{
+		children: func(node map[string]any) []any {
+			return field(node, key)
+		},
+		withChildren: func(node map[string]any, children []any) map[string]any {
+			out := copyMap(node)
+			out[key] = append([]any(nil), children...)
+			return out
+		},
+	}
+}
+
+// dataPointsLevel is the level of the data points nested under the data field of a metric
+func dataPointsLevel() level {
+	dataKey := func(metric map[string]any) string {
+		for _, key := range metricDataFields {
+			if _, ok := metric[key]; ok {
+				return key
+			}
+		}
+		return ""
+	}
+	return level{
+		children: func(metric map[string]any) []any {
+			return field(metric[dataKey(metric)], "dataPoints")
+		},
+		withChildren: func(metric map[string]any, children []any) map[string]any {
+			key := dataKey(metric)
+			data := copyMap(asMap(metric[key]))
+			data["dataPoints"] = append([]any(nil), children...)
+			out := copyMap(metric)
+			out[key] = data
+			return out
+		},
+	}
+}
+
+func copyMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m)+1)
+	for key, value := range m {
+		out[key] = value
+	}
+	return out
+}
@@ -0,0 +1,163 @@
+package otlp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+func jsonSize(payload any) (int, error) {
+	data, err := json.Marshal(payload)
+	return len(data), err
+}
+
+// spansPayload builds a trace payload with the given number of spans in each scope of each resource
+func spansPayload(resources, scopes, spans int) map[string]any {
+	var resourceSpans []any
+	for r := range resources {
+		var scopeSpans []any
+		for sc := range scopes {
+			var items []any
+			for sp := range spans {
+				items = append(items, map[string]any{"name": fmt.Sprintf("r%d-s%d-span%d", r, sc, sp)})
+			}
+			scopeSpans = append(scopeSpans, map[string]any{"scope": map[string]any{"name": fmt.Sprintf("scope-%d", sc)}, "spans": items})
+		}
+		resourceSpans = append(resourceSpans, map[string]any{"resource": map[string]any{"attributes": []any{}}, "scopeSpans": scopeSpans})
+	}
+	return map[string]any{"resourceSpans": resourceSpans}
+}
+
+func TestSplitPayloadTraces(t *testing.T) {
+	for _, tc := range []struct {
+		name                     string
+		resources, scopes, spans int
+		maxBytes                 int
+		minPieces                int
+	}{
+		{name: "fits", resources: 2, scopes: 2, spans: 2, maxBytes: 1 << 20, minPieces: 1},
+		{name: "resources", resources: 4, scopes: 1, spans: 1, maxBytes: 200, minPieces: 2},
+		{name: "scopes", resources: 1, scopes: 4, spans: 1, maxBytes: 200, minPieces: 2},
+		{name: "spans", resources: 1, scopes: 1, spans: 20, maxBytes: 200, minPieces: 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			payload := spansPayload(tc.resources, tc.scopes, tc.spans)
+			pieces, _, err := SplitPayload(payload, s.TelemetryTraces, tc.maxBytes, jsonSize)
+			if err != nil {
+				t.Fatalf("SplitPayload returned error: %v", err)
+			}
+			if len(pieces) < tc.minPieces {
+				t.Errorf("Expected at least %d pieces, got %d", tc.minPieces, len(pieces))
+			}
+			var names []string
+			for _, piece := range pieces {
+				if n, _ := jsonSize(piece); n > tc.maxBytes {
+					t.Errorf("Piece of %d bytes exceeds the %d byte limit", n, tc.maxBytes)
+				}
+				if _, err := ToProto(piece, s.TelemetryTraces); err != nil {
+					t.Errorf("Piece is not a valid OTLP payload: %v", err)
+				}
+				data, _ := json.Marshal(piece)
+				names = append(names, string(data))
+			}
+			if got, want := CountItems(map[string]any{"resourceSpans": concatResources(pieces)}, s.TelemetryTraces), tc.resources*tc.scopes*tc.spans; got != want {
+				t.Errorf("Expected %d spans across pieces, got %d", want, got)
+			}
+			// Items keep their original order
+			joined := strings.Join(names, "")
+			last := -1
+			for r := range tc.resources {
+				for sc := range tc.scopes {
+					for sp := range tc.spans {
+						idx := strings.Index(joined, fmt.Sprintf(`"r%d-s%d-span%d"`, r, sc, sp))
+						if idx < last {
+							t.Fatalf("Span r%d-s%d-span%d is out of order", r, sc, sp)
+						}
+						last = idx
+					}
+				}
+			}
+		})
+	}
+}
+
+func concatResources(pieces []any) []any {
+	var all []any
+	for _, piece := range pieces {
+		all = append(all, field(piece, "resourceSpans")...)
+	}
+	return all
+}
+
+func TestSplitPayloadMetricDataPoints(t *testing.T) {
+	var points []any
+	for i := range 10 {
+		points = append(points, map[string]any{"asInt": fmt.Sprint(i), "timeUnixNano": "1700000000000000000"})
+	}
+	payload := map[string]any{"resourceMetrics": []any{map[string]any{"scopeMetrics": []any{map[string]any{"metrics": []any{
+		map[string]any{"name": "requests", "sum": map[string]any{"aggregationTemporality": 2, "isMonotonic": true, "dataPoints": points}},
+	}}}}}}
+
+	pieces, _, err := SplitPayload(payload, s.TelemetryMetrics, 250, jsonSize)
+	if err != nil {
+		t.Fatalf("SplitPayload returned error: %v", err)
+	}
+	if len(pieces) < 2 {
+		t.Fatalf("Expected the data points to be split, got %d piece", len(pieces))
+	}
+	total := 0
+	for _, piece := range pieces {
+		total += CountItems(piece, s.TelemetryMetrics)
+		metric := asMap(field(field(field(piece, "resourceMetrics")[0], "scopeMetrics")[0], "metrics")[0])
+		sum := asMap(metric["sum"])
+		if metric["name"] != "requests" || sum["isMonotonic"] != true {
+			t.Errorf("Expected every piece to keep the metric definition, got %v", metric)
+		}
+	}
+	if total != 10 {
+		t.Errorf("Expected 10 data points across pieces, got %d", total)
+	}
+	// The original payload is left untouched
+	if CountItems(payload, s.TelemetryMetrics) != 10 {
+		t.Error("SplitPayload modified its input")
+	}
+}
+
+func TestSplitPayloadOversizedItem(t *testing.T) {
+	payload := map[string]any{"resourceLogs": []any{map[string]any{"scopeLogs": []any{map[string]any{"logRecords": []any{
+		map[string]any{"body": map[string]any{"stringValue": "small"}},
+		map[string]any{"body": map[string]any{"stringValue": strings.Repeat("x", 500)}},
+	}}}}}}
+	pieces, oversized, err := SplitPayload(payload, s.TelemetryLogs, 200, jsonSize)
+	if err != nil {
+		t.Fatalf("SplitPayload returned error: %v", err)
+	}
+	if len(pieces) != 1 || CountItems(pieces[0], s.TelemetryLogs) != 1 {
+		t.Errorf("Expected the small log record to be kept in a piece of its own, got %v", pieces)
+	}
+	if len(oversized) != 1 {
+		t.Fatalf("Expected one OversizedItemError, got %d", len(oversized))
+	}
+	item := oversized[0]
+	if item.MaxBytes != 200 || item.Size <= 500 || item.TelemetryType != s.TelemetryLogs || CountItems(item.Payload, s.TelemetryLogs) != 1 {
+		t.Errorf("Unexpected error fields: %+v", item)
+	}
+}
+
+func TestSplitPayloadErrors(t *testing.T) {
+	_, _, err := SplitPayload(map[string]any{}, s.TelemetryType(99), 10, jsonSize)
+	var typeErr *UnknownTelemetryTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("Expected UnknownTelemetryTypeError, got %T: %v", err, err)
+	}
+
+	sizeErr := errors.New("size failed")
+	_, _, err = SplitPayload(spansPayload(1, 1, 1), s.TelemetryTraces, 10, func(any) (int, error) { return 0, sizeErr })
+	if !errors.Is(err, sizeErr) {
+		t.Errorf("Expected the size error to be returned, got %v", err)
+	}
+}
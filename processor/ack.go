@@ -0,0 +1,131 @@
+package processor
+
+import "sync"
+
+// AckTracker follows the jobs of every dispatched source line and reports the highest
+// line such that it and every line before it have been fully handled, that is delivered
+// or kept in a dead-letter file. Workers may complete jobs in any order; the watermark
+// only advances over contiguous lines and never past a line with a job that failed
+// without being kept. Lines with a failed job are counted separately.
+type AckTracker struct {
+	mu      sync.Mutex
+	pending map[int]int
+	// failing holds the pending lines with a failed job until their last job is done
+	failing map[int]bool
+	failed  int
+	// heldFrom is the first line with a job that failed without being kept, or 0. The
+	// watermark never reaches it, so no offsets are recorded from it on.
+	heldFrom   int
+	ends       map[int]int64
+	dispatched int
+	acked      int
+	ackedEnd   int64
+}
+
+// NewAckTracker creates an AckTracker with no dispatched lines
+func NewAckTracker() *AckTracker {
+	return NewAckTrackerFrom(0, 0)
+}
+
+// NewAckTrackerFrom creates an AckTracker for an input resumed after line, which ends
+// at byte offset end. Lines up to and including it count as acknowledged.
+func NewAckTrackerFrom(line int, end int64) *AckTracker {
+	return &AckTracker{pending: make(map[int]int), failing: make(map[int]bool), ends: make(map[int]int64), dispatched: line, acked: line, ackedEnd: end}
+}
+
+// Add registers a source line and the number of jobs it produced. Lines must be added
+// in increasing order and before any of their jobs are handed to a worker. Lines
+// without jobs, such as blank or unparsable ones, are added with zero jobs.
+func (a *AckTracker) Add(lineNum, jobs int) {
+	a.AddLine(lineNum, jobs, 0)
+}
+
+// AddLine registers a source line like Add, along with the byte offset at which it ends
+// in the input, which Position reports once the line is acknowledged
+func (a *AckTracker) AddLine(lineNum, jobs int, end int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.held(lineNum) {
+		a.ends[lineNum] = end
+	}
+	if jobs > 0 {
+		a.pending[lineNum] = jobs
+	}
+	a.dispatched = lineNum
+	a.advance()
+}
+
+// Done marks one job of a source line as handled
+func (a *AckTracker) Done(lineNum int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.done(lineNum)
+}
+
+// Fail marks one job of a source line as failed. A job that was kept, for instance in a
+// dead-letter file, counts as handled. Otherwise the watermark stops before the line for
+// the rest of the run, so a resumed run sends it again.
+func (a *AckTracker) Fail(lineNum int, kept bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failing[lineNum] = true
+	if !kept && !a.held(lineNum) {
+		a.heldFrom = lineNum
+		for line := range a.ends {
+			if line >= lineNum {
+				delete(a.ends, line)
+			}
+		}
+	}
+	a.done(lineNum)
+}
+
+func (a *AckTracker) done(lineNum int) {
+	if a.pending[lineNum] > 1 {
+		a.pending[lineNum]--
+		return
+	}
+	delete(a.pending, lineNum)
+	if a.failing[lineNum] {
+		delete(a.failing, lineNum)
+		a.failed++
+	}
+	a.advance()
+}
+
+// held reports whether lineNum is at or after a line the watermark cannot pass
+func (a *AckTracker) held(lineNum int) bool {
+	return a.heldFrom != 0 && lineNum >= a.heldFrom
+}
+
+// LastHandled returns the last line up to which every line has been fully handled
+func (a *AckTracker) LastHandled() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.acked
+}
+
+// Failed returns the number of lines with at least one failed job
+func (a *AckTracker) Failed() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.failed
+}
+
+// Position returns the last fully handled line and the byte offset at which it ends
+func (a *AckTracker) Position() (int, int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.acked, a.ackedEnd
+}
+
+func (a *AckTracker) advance() {
+	for a.acked < a.dispatched && !a.held(a.acked+1) {
+		if _, ok := a.pending[a.acked+1]; ok {
+			return
+		}
+		a.acked++
+		a.ackedEnd = a.ends[a.acked]
+		delete(a.ends, a.acked)
+	}
+}
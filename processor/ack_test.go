@@ -0,0 +1,139 @@
+package processor
+
+import (
+	"sync"
+	"testing"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+)
+
+type ackStep struct {
+	add  bool
+	line int
+	jobs int
+}
+
+func TestAckTracker(t *testing.T) {
+	newTest := func(steps []ackStep, expected int) c.CharacterizationTest[int] {
+		return c.NewCharacterizationTest(expected, nil, func() (int, error) {
+			acks := NewAckTracker()
+			for _, step := range steps {
+				if step.add {
+					acks.Add(step.line, step.jobs)
+				} else {
+					acks.Done(step.line)
+				}
+			}
+			return acks.LastHandled(), nil
+		})
+	}
+	tests := []c.CharacterizationTest[int]{
+		newTest(nil, 0),
+		newTest([]ackStep{{add: true, line: 1, jobs: 1}}, 0),
+		newTest([]ackStep{{add: true, line: 1, jobs: 1}, {line: 1}}, 1),
+		newTest([]ackStep{{add: true, line: 1, jobs: 0}, {add: true, line: 2, jobs: 0}}, 2),
+		// Line 2 completes before line 1, the watermark waits for line 1
+		newTest([]ackStep{{add: true, line: 1, jobs: 1}, {add: true, line: 2, jobs: 1}, {line: 2}}, 0),
+		newTest([]ackStep{{add: true, line: 1, jobs: 1}, {add: true, line: 2, jobs: 1}, {line: 2}, {line: 1}}, 2),
+		// A line with several jobs is only acknowledged once all of them are done
+		newTest([]ackStep{{add: true, line: 1, jobs: 3}, {line: 1}, {line: 1}}, 0),
+		newTest([]ackStep{{add: true, line: 1, jobs: 3}, {line: 1}, {line: 1}, {line: 1}}, 1),
+		// Blank lines after a pending line are skipped once it completes
+		newTest([]ackStep{{add: true, line: 1, jobs: 1}, {add: true, line: 2, jobs: 0}, {add: true, line: 3, jobs: 0}, {add: true, line: 4, jobs: 2}, {line: 1}}, 3),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestAckTrackerConcurrentDone(t *testing.T) {
+	acks := NewAckTracker()
+	const lines = 1000
+	for line := 1; line <= lines; line++ {
+		acks.Add(line, 2)
+	}
+	var wg sync.WaitGroup
+	for line := lines; line >= 1; line-- {
+		wg.Add(2)
+		go func() { defer wg.Done(); acks.Done(line) }()
+		go func() { defer wg.Done(); acks.Done(line) }()
+	}
+	wg.Wait()
+	if got := acks.LastHandled(); got != lines {
+		t.Errorf("Expected every line to be acknowledged, got %d", got)
+	}
+}
+
+func TestAckTrackerPosition(t *testing.T) {
+	acks := NewAckTrackerFrom(10, 500)
+	if line, offset := acks.Position(); line != 10 || offset != 500 {
+		t.Errorf("Expected resumed position 10 at 500, got %d at %d", line, offset)
+	}
+	acks.AddLine(11, 1, 550)
+	acks.AddLine(12, 0, 560)
+	acks.AddLine(13, 2, 600)
+	acks.Done(13)
+	acks.Done(13)
+	if line, offset := acks.Position(); line != 10 || offset != 500 {
+		t.Errorf("Expected position to wait for line 11, got %d at %d", line, offset)
+	}
+	acks.Done(11)
+	if line, offset := acks.Position(); line != 13 || offset != 600 {
+		t.Errorf("Expected position 13 at 600 once line 11 is done, got %d at %d", line, offset)
+	}
+}
+
+func TestAckTrackerFail(t *testing.T) {
+	acks := NewAckTracker()
+	acks.Add(1, 1)
+	acks.Add(2, 2)
+	acks.Add(3, 1)
+	acks.Done(1)
+	acks.Done(2)
+	acks.Fail(2, false)
+	acks.Fail(3, true)
+	if got := acks.LastHandled(); got != 1 {
+		t.Errorf("Expected the watermark to stop before the failed line 2, got %d", got)
+	}
+	acks.Add(4, 0)
+	if got := acks.LastHandled(); got != 1 {
+		t.Errorf("Expected the watermark to stay before the failed line, got %d", got)
+	}
+	if got := acks.Failed(); got != 2 {
+		t.Errorf("Expected 2 failed lines, got %d", got)
+	}
+}
+
+func TestAckTrackerFailKept(t *testing.T) {
+	acks := NewAckTracker()
+	acks.Add(1, 1)
+	acks.Add(2, 1)
+	acks.Fail(1, true)
+	acks.Done(2)
+	if got := acks.LastHandled(); got != 2 {
+		t.Errorf("Expected a kept failure to count as handled, got %d", got)
+	}
+	if got := acks.Failed(); got != 1 {
+		t.Errorf("Expected 1 failed line, got %d", got)
+	}
+}
+
+func TestAckTrackerHeldLineStopsRecordingOffsets(t *testing.T) {
+	acks := NewAckTracker()
+	acks.AddLine(1, 1, 10)
+	acks.AddLine(2, 1, 20)
+	acks.AddLine(3, 1, 30)
+	acks.Fail(2, false)
+	for line := 4; line <= 1000; line++ {
+		acks.AddLine(line, 1, int64(line*10))
+		acks.Done(line)
+	}
+	if len(acks.ends) != 1 {
+		t.Errorf("Expected only the offset of line 1 to be kept, got %d offsets", len(acks.ends))
+	}
+	acks.Done(1)
+	if line, offset := acks.Position(); line != 1 || offset != 10 {
+		t.Errorf("Expected position 1 at 10 before the held line, got %d at %d", line, offset)
+	}
+	if len(acks.ends) != 0 || len(acks.pending) != 1 {
+		t.Errorf("Expected only line 3 to stay pending, got %d offsets and %d pending lines", len(acks.ends), len(acks.pending))
+	}
+}
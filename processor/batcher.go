@@ -0,0 +1,201 @@
+package processor
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/otlp"
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// Batcher merges the resourceSpans, resourceLogs and resourceMetrics arrays of jobs from
+// consecutive lines into a single export request per telemetry type and destination. A batch is flushed
+// once it reaches MaxItems spans, log records or data points, once its resources reach
+// MaxBytes of JSON, or FlushInterval after its first line was added, whichever comes first.
+type Batcher struct {
+	MaxItems      int
+	MaxBytes      int
+	FlushInterval time.Duration
+	out           chan<- s.TelemetryJob
+	pending       map[batchKey]*batch
+}
+
+// batchKey identifies the batch of one telemetry type bound for one destination
+type batchKey struct {
+	telemetryType s.TelemetryType
+	endpoint      string
+}
+
+// batch accumulates the resources of one telemetry type and destination
+type batch struct {
+	resources []any
+	items     int
+	bytes     int
+	lines     []int
+	deadline  time.Time
+}
+
+// NewBatcher creates a Batcher configured from cfg that emits merged jobs on out
+func NewBatcher(cfg *config.Config, out chan<- s.TelemetryJob) *Batcher {
+	return &Batcher{
+		MaxItems:      cfg.BatchMaxItems,
+		MaxBytes:      cfg.BatchMaxBytes,
+		FlushInterval: cfg.BatchFlushInterval,
+		out:           out,
+		pending:       make(map[batchKey]*batch),
+	}
+}
+
+// Run batches the jobs received on in until it is closed, then flushes whatever is
+// pending and closes the output channel
+func (b *Batcher) Run(in <-chan s.TelemetryJob) {
+	defer close(b.out)
+	timer := time.NewTimer(0)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case job, ok := <-in:
+			if !ok {
+				b.flushAll()
+				return
+			}
+			b.Add(job)
+		case now := <-timer.C:
+			b.flushExpired(now)
+		}
+		b.resetTimer(timer)
+	}
+}
+
+// Add appends the resources of a single-line job to the batch of its telemetry type and
+// destination, flushing the batch before the job when the job would push it over a limit
+func (b *Batcher) Add(job s.TelemetryJob) {
+	field := resourceField(job.TelemetryType)
+	resources, _ := payloadField(job.Payload, field).([]any)
+	items := otlp.CountItems(job.Payload, job.TelemetryType)
+	size := jsonSize(resources)
+
+	key := batchKey{telemetryType: job.TelemetryType, endpoint: job.Endpoint}
+	current := b.pending[key]
+	if current != nil && b.exceeds(current.items+items, current.bytes+size) {
+		b.flush(key)
+		current = nil
+	}
+	if current == nil {
+		current = &batch{}
+		if b.FlushInterval > 0 {
+			current.deadline = time.Now().Add(b.FlushInterval)
+		}
+		b.pending[key] = current
+	}
+
+	current.resources = append(current.resources, resources...)
+	current.items += items
+	current.bytes += size
+	current.lines = append(current.lines, job.SourceLines()...)
+
+	if b.reached(current.items, current.bytes) {
+		b.flush(key)
+	}
+}
+
+// exceeds reports whether a batch with these totals would be over a limit
+func (b *Batcher) exceeds(items, bytes int) bool {
+	return (b.MaxItems > 0 && items > b.MaxItems) || (b.MaxBytes > 0 && bytes > b.MaxBytes)
+}
+
+// reached reports whether a batch with these totals is full
+func (b *Batcher) reached(items, bytes int) bool {
+	return (b.MaxItems > 0 && items >= b.MaxItems) || (b.MaxBytes > 0 && bytes >= b.MaxBytes)
+}
+
+// flush emits a pending batch as one job
+func (b *Batcher) flush(key batchKey) {
+	current := b.pending[key]
+	if current == nil {
+		return
+	}
+	delete(b.pending, key)
+	b.out <- s.TelemetryJob{
+		Endpoint:      key.endpoint,
+		Payload:       map[string]any{resourceField(key.telemetryType): current.resources},
+		TelemetryType: key.telemetryType,
+		LineNum:       current.lines[0],
+		Lines:         current.lines,
+	}
+}
+
+// flushAll emits every pending batch, ordered by telemetry type and destination
+func (b *Batcher) flushAll() {
+	keys := make([]batchKey, 0, len(b.pending))
+	for key := range b.pending {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].telemetryType != keys[j].telemetryType {
+			return keys[i].telemetryType < keys[j].telemetryType
+		}
+		return keys[i].endpoint < keys[j].endpoint
+	})
+	for _, key := range keys {
+		b.flush(key)
+	}
+}
+
+func (b *Batcher) flushExpired(now time.Time) {
+	for key, current := range b.pending {
+		if !current.deadline.IsZero() && !now.Before(current.deadline) {
+			b.flush(key)
+		}
+	}
+}
+
+// resetTimer arms the timer for the earliest pending deadline
+func (b *Batcher) resetTimer(timer *time.Timer) {
+	var next time.Time
+	for _, current := range b.pending {
+		if !current.deadline.IsZero() && (next.IsZero() || current.deadline.Before(next)) {
+			next = current.deadline
+		}
+	}
+	if next.IsZero() {
+		timer.Stop()
+		return
+	}
+	timer.Reset(time.Until(next))
+}
+
+// resourceField returns the top-level payload field holding the resources of a telemetry type
+func resourceField(telemetryType s.TelemetryType) string {
+	switch telemetryType {
+	case s.TelemetryLogs:
+		return resourceLogsField
+	case s.TelemetryMetrics:
+		return resourceMetricsField
+	default:
+		return resourceSpansField
+	}
+}
+
+func payloadField(payload any, field string) any {
+	switch p := payload.(type) {
+	case map[string]any:
+		return p[field]
+	case s.TelemetryData:
+		return p[field]
+	default:
+		return nil
+	}
+}
+
+func jsonSize(value any) int {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
@@ -0,0 +1,104 @@
+package processor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// spanJob builds a single-line trace job carrying one resource with the given number of spans
+func spanJob(lineNum, spans int) s.TelemetryJob {
+	items := make([]any, spans)
+	for i := range items {
+		items[i] = map[string]any{"name": fmt.Sprintf("span-%d-%d", lineNum, i)}
+	}
+	return s.TelemetryJob{
+		Endpoint:      "http://collector/v1/traces",
+		Payload:       map[string]any{resourceSpansField: []any{map[string]any{"scopeSpans": []any{map[string]any{"spans": items}}}}},
+		TelemetryType: s.TelemetryTraces,
+		LineNum:       lineNum,
+	}
+}
+
+func logJob(lineNum int) s.TelemetryJob {
+	return s.TelemetryJob{
+		Endpoint:      "http://collector/v1/logs",
+		Payload:       map[string]any{resourceLogsField: []any{map[string]any{"scopeLogs": []any{map[string]any{"logRecords": []any{map[string]any{}}}}}}},
+		TelemetryType: s.TelemetryLogs,
+		LineNum:       lineNum,
+	}
+}
+
+// runBatcher feeds jobs through a Batcher and describes every emitted batch as type:lines:resources
+func runBatcher(b *Batcher, jobs []s.TelemetryJob) []string {
+	in := make(chan s.TelemetryJob)
+	out := make(chan s.TelemetryJob, len(jobs)+3)
+	b.out = out
+	b.pending = make(map[batchKey]*batch)
+	go b.Run(in)
+	for _, job := range jobs {
+		in <- job
+	}
+	close(in)
+
+	var batches []string
+	for job := range out {
+		resources, _ := payloadField(job.Payload, resourceField(job.TelemetryType)).([]any)
+		batches = append(batches, fmt.Sprintf("%s:%v:%d", job.TelemetryType, job.SourceLines(), len(resources)))
+	}
+	return batches
+}
+
+func TestBatcher(t *testing.T) {
+	newTest := func(b *Batcher, jobs []s.TelemetryJob, expected string) c.CharacterizationTest[string] {
+		return c.NewCharacterizationTest(expected, nil, func() (string, error) {
+			return fmt.Sprint(runBatcher(b, jobs)), nil
+		})
+	}
+	tests := []c.CharacterizationTest[string]{
+		// Everything fits, one batch per type flushed on close
+		newTest(&Batcher{MaxItems: 100}, []s.TelemetryJob{spanJob(1, 2), logJob(2), spanJob(3, 2)}, "[Traces:[1 3]:2 Logs:[2]:1]"),
+		// A full batch is flushed as soon as it reaches MaxItems
+		newTest(&Batcher{MaxItems: 4}, []s.TelemetryJob{spanJob(1, 2), spanJob(2, 2), spanJob(3, 1)}, "[Traces:[1 2]:2 Traces:[3]:1]"),
+		// A line that would overflow the batch starts a new one
+		newTest(&Batcher{MaxItems: 4}, []s.TelemetryJob{spanJob(1, 3), spanJob(2, 3)}, "[Traces:[1]:1 Traces:[2]:1]"),
+		// A single line above the limit is still sent on its own
+		newTest(&Batcher{MaxItems: 2}, []s.TelemetryJob{spanJob(1, 5), spanJob(2, 1)}, "[Traces:[1]:1 Traces:[2]:1]"),
+		// Byte limit
+		newTest(&Batcher{MaxBytes: 80}, []s.TelemetryJob{spanJob(1, 1), spanJob(2, 1), spanJob(3, 1)}, "[Traces:[1]:1 Traces:[2]:1 Traces:[3]:1]"),
+		newTest(&Batcher{MaxBytes: 1000}, []s.TelemetryJob{spanJob(1, 1), spanJob(2, 1), spanJob(3, 1)}, "[Traces:[1 2 3]:3]"),
+		newTest(&Batcher{}, nil, "[]"),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestBatcherFlushInterval(t *testing.T) {
+	in := make(chan s.TelemetryJob)
+	out := make(chan s.TelemetryJob, 2)
+	b := &Batcher{MaxItems: 100, FlushInterval: 20 * time.Millisecond, out: out, pending: make(map[batchKey]*batch)}
+	go b.Run(in)
+	defer close(in)
+
+	in <- spanJob(1, 1)
+	in <- spanJob(2, 1)
+	select {
+	case job := <-out:
+		if fmt.Sprint(job.SourceLines()) != "[1 2]" || job.LineNum != 1 {
+			t.Errorf("Expected a batch covering lines 1 and 2, got lines=%v line=%d", job.SourceLines(), job.LineNum)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the batch to be flushed by the flush interval")
+	}
+}
+
+func TestBatcherSeparatesDestinations(t *testing.T) {
+	other := spanJob(2, 1)
+	other.Endpoint = "http://other/v1/traces"
+	batches := runBatcher(&Batcher{MaxItems: 100}, []s.TelemetryJob{spanJob(1, 1), other, spanJob(3, 1)})
+	if fmt.Sprint(batches) != "[Traces:[1 3]:2 Traces:[2]:1]" {
+		t.Errorf("Expected one batch per destination, got %v", batches)
+	}
+}
@@ -0,0 +1,166 @@
+package processor
+
+import (
+	"bufio"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/laiambryant/telemetry-ingestor/checkpoint"
+	"github.com/laiambryant/telemetry-ingestor/config"
+)
+
+// defaultCheckpointInterval is used when no checkpoint interval is configured
+const defaultCheckpointInterval = 5 * time.Second
+
+// fileCheckpoint records the delivery progress of one input in the checkpoint file.
+// The position saved is the acknowledgement watermark of the run, so every line up to
+// it has been handled even though workers complete out of order.
+type fileCheckpoint struct {
+	file     *checkpoint.File
+	path     string
+	interval time.Duration
+	dryRun   bool
+
+	mu    sync.Mutex
+	entry checkpoint.Entry
+	saved bool
+}
+
+// openCheckpoint prepares checkpointing of the input at filePath and, with --resume,
+// loads the position to resume from. It returns nil when no checkpoint file is
+// configured and for standard input, which cannot be resumed.
+func openCheckpoint(cfg *config.Config, filePath string) (*fileCheckpoint, error) {
+	if cfg.CheckpointPath == "" {
+		return nil, nil
+	}
+	if filePath == StdinPath {
+		slog.Warn("Standard input cannot be checkpointed, ignoring --checkpoint")
+		return nil, nil
+	}
+
+	file, err := checkpoint.Open(cfg.CheckpointPath)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, n, err := checkpoint.Fingerprint(filePath, checkpoint.FingerprintSize)
+	if err != nil {
+		return nil, &FileReadError{FilePath: filePath, Err: err}
+	}
+	interval := cfg.CheckpointInterval
+	if interval <= 0 {
+		interval = defaultCheckpointInterval
+	}
+	fc := &fileCheckpoint{
+		file:     file,
+		path:     filePath,
+		interval: interval,
+		dryRun:   cfg.DryRun,
+		entry:    checkpoint.Entry{Fingerprint: fingerprint, FingerprintBytes: n},
+	}
+	if !cfg.Resume {
+		return fc, nil
+	}
+
+	saved, ok := file.Get(filePath)
+	switch {
+	case !ok:
+		slog.Info("No checkpoint for file, starting from the beginning", "file", filePath, "checkpoint", file.Path())
+	case !saved.Matches(filePath):
+		slog.Warn("File changed since it was checkpointed, starting from the beginning", "file", filePath, "checkpoint", file.Path())
+	default:
+		fc.entry.Line, fc.entry.Offset = saved.Line, saved.Offset
+	}
+	return fc, nil
+}
+
+// start returns the last delivered line and the byte offset at which it ends
+func (fc *fileCheckpoint) start() (int, int64) {
+	if fc == nil {
+		return 0, 0
+	}
+	return fc.entry.Line, fc.entry.Offset
+}
+
+// resume moves input past the data delivered before the checkpoint
+func (fc *fileCheckpoint) resume(input io.Reader) error {
+	line, offset := fc.start()
+	if offset == 0 {
+		return nil
+	}
+	slog.Info("Resuming from checkpoint", "file", fc.path, "line", line, "offset", offset)
+	if seeker, ok := input.(io.Seeker); ok {
+		if _, err := seeker.Seek(offset, io.SeekStart); err == nil {
+			return nil
+		}
+	}
+	// Compressed inputs are decoded and skipped, offsets refer to the decompressed data
+	if _, err := io.CopyN(io.Discard, input, offset); err != nil && err != io.EOF {
+		return &FileReadError{FilePath: fc.path, Err: err}
+	}
+	return nil
+}
+
+// countOffsets makes scanner add the bytes of every line it reads, line ending
+// included, to offset
+func countOffsets(scanner *bufio.Scanner, offset *int64) {
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		*offset += int64(advance)
+		return advance, token, err
+	})
+}
+
+// track saves the position of acks every interval until the returned function is
+// called, which saves it a last time
+func (fc *fileCheckpoint) track(acks *AckTracker) func() {
+	if fc == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(fc.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fc.save(acks)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		fc.save(acks)
+		if fc.dryRun {
+			return
+		}
+		line, offset := fc.start()
+		slog.Info("Checkpoint saved", "file", fc.path, "checkpoint", fc.file.Path(), "line", line, "offset", offset)
+	}
+}
+
+// save writes the position of acks to the checkpoint file when it moved. Dry runs
+// deliver nothing and leave the checkpoint file untouched.
+func (fc *fileCheckpoint) save(acks *AckTracker) {
+	if fc.dryRun {
+		return
+	}
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	line, offset := acks.Position()
+	if fc.saved && line == fc.entry.Line && offset == fc.entry.Offset {
+		return
+	}
+	fc.entry.Line, fc.entry.Offset = line, offset
+	if err := fc.file.Save(fc.path, fc.entry); err != nil {
+		slog.Warn("Failed to save checkpoint", "file", fc.path, "error", err)
+		return
+	}
+	fc.saved = true
+}
@@ -0,0 +1,207 @@
+package processor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/laiambryant/telemetry-ingestor/checkpoint"
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+// traceLines returns n trace lines, each naming its line number
+func traceLines(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, `{"resourceSpans":[{"scopeSpans":[],"line":%d}]}`+"\n", i)
+	}
+	return b.String()
+}
+
+// sentTraceLines returns the sorted line numbers carried by the trace requests the mock received
+func sentTraceLines(mock *testutil.MockOTelCollector) string {
+	var lines []int
+	for _, payload := range mock.ReceivedTraces {
+		for _, resource := range payload["resourceSpans"].([]any) {
+			lines = append(lines, int(resource.(map[string]any)["line"].(float64)))
+		}
+	}
+	sort.Ints(lines)
+	return strings.Trim(strings.Join(strings.Fields(fmt.Sprint(lines)), ","), "[]")
+}
+
+func gzipped(t *testing.T, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// saveCheckpoint records input as delivered up to line
+func saveCheckpoint(t *testing.T, statePath, input string, line int, offset int64) {
+	t.Helper()
+	state, err := checkpoint.Open(statePath)
+	if err != nil {
+		t.Fatalf("failed to open checkpoint: %v", err)
+	}
+	fingerprint, n, err := checkpoint.Fingerprint(input, checkpoint.FingerprintSize)
+	if err != nil {
+		t.Fatalf("failed to fingerprint %s: %v", input, err)
+	}
+	if err := state.Save(input, checkpoint.Entry{Fingerprint: fingerprint, FingerprintBytes: n, Line: line, Offset: offset}); err != nil {
+		t.Fatalf("failed to save checkpoint: %v", err)
+	}
+}
+
+func loadCheckpoint(t *testing.T, statePath, input string) checkpoint.Entry {
+	t.Helper()
+	state, err := checkpoint.Open(statePath)
+	if err != nil {
+		t.Fatalf("failed to open checkpoint: %v", err)
+	}
+	entry, ok := state.Get(input)
+	if !ok {
+		t.Fatalf("Expected a checkpoint entry for %s", input)
+	}
+	return entry
+}
+
+func checkpointConfig(mock *testutil.MockOTelCollector, statePath string, resume bool) *config.Config {
+	return &config.Config{
+		OtelEndpoint:      mock.TracesURL(),
+		MaxBufferCapacity: 1048576,
+		SendAll:           true,
+		Workers:           4,
+		CheckpointPath:    statePath,
+		Resume:            resume,
+	}
+}
+
+func TestIngestTelemetryResumesFromCheckpoint(t *testing.T) {
+	content := traceLines(6)
+	offset := int64(len(strings.Join(strings.SplitAfter(content, "\n")[:4], "")))
+	for _, name := range []string{"capture.jsonl", "capture.jsonl.gz"} {
+		data := []byte(content)
+		if strings.HasSuffix(name, ".gz") {
+			data = gzipped(t, content)
+		}
+		input := writeInputFile(t, name, data)
+		statePath := filepath.Join(t.TempDir(), "state.json")
+		saveCheckpoint(t, statePath, input, 4, offset)
+
+		mock := testutil.NewMockOTelCollector()
+		if err := IngestTelemetry(context.Background(), input, checkpointConfig(mock, statePath, true)); err != nil {
+			t.Fatalf("%s: IngestTelemetry returned error: %v", name, err)
+		}
+		if lines := sentTraceLines(mock); lines != "5,6" {
+			t.Errorf("%s: expected lines 5 and 6 to be sent, got %q", name, lines)
+		}
+		if entry := loadCheckpoint(t, statePath, input); entry.Line != 6 || entry.Offset != int64(len(content)) {
+			t.Errorf("%s: expected checkpoint at line 6, offset %d, got line %d, offset %d", name, len(content), entry.Line, entry.Offset)
+		}
+		mock.Close()
+	}
+}
+
+func TestIngestTelemetryCheckpointThenResume(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	content := traceLines(20)
+	input := writeInputFile(t, "capture.jsonl", []byte(content))
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	if err := IngestTelemetry(context.Background(), input, checkpointConfig(mock, statePath, false)); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	if entry := loadCheckpoint(t, statePath, input); entry.Line != 20 || entry.Offset != int64(len(content)) {
+		t.Errorf("Expected checkpoint at the end of the file, got line %d, offset %d", entry.Line, entry.Offset)
+	}
+
+	if err := IngestTelemetry(context.Background(), input, checkpointConfig(mock, statePath, true)); err != nil {
+		t.Fatalf("IngestTelemetry with --resume returned error: %v", err)
+	}
+	if traces, _, _, _ := mock.GetStats(); traces != 20 {
+		t.Errorf("Expected resuming a delivered file to send nothing more, got %d trace requests in total", traces)
+	}
+}
+
+func TestIngestTelemetryResumeIgnoresChangedFile(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	input := writeInputFile(t, "capture.jsonl", []byte(traceLines(3)))
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	saveCheckpoint(t, statePath, input, 2, 80)
+	if err := os.WriteFile(input, []byte(strings.Replace(traceLines(3), `"line":1`, `"line":0`, 1)), 0o644); err != nil {
+		t.Fatalf("failed to rewrite %s: %v", input, err)
+	}
+
+	if err := IngestTelemetry(context.Background(), input, checkpointConfig(mock, statePath, true)); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	if traces, _, _, _ := mock.GetStats(); traces != 3 {
+		t.Errorf("Expected a changed file to be sent from the start, got %d trace requests", traces)
+	}
+}
+
+func TestIngestTelemetryCheckpointHoldsFailedLines(t *testing.T) {
+	content := traceLines(6)
+	input := writeInputFile(t, "capture.jsonl", []byte(content))
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	failing := testutil.NewMockOTelCollector()
+	defer failing.Close()
+	failing.QueueResponses(
+		testutil.MockResponse{StatusCode: 200},
+		testutil.MockResponse{StatusCode: 200},
+		testutil.MockResponse{StatusCode: 500},
+	)
+	cfg := checkpointConfig(failing, statePath, false)
+	cfg.Workers = 1
+	if err := IngestTelemetry(context.Background(), input, cfg); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	offset := int64(len(strings.Join(strings.SplitAfter(content, "\n")[:2], "")))
+	if entry := loadCheckpoint(t, statePath, input); entry.Line != 2 || entry.Offset != offset {
+		t.Errorf("Expected checkpoint to stop before the failed line 3, got line %d, offset %d", entry.Line, entry.Offset)
+	}
+
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	if err := IngestTelemetry(context.Background(), input, checkpointConfig(mock, statePath, true)); err != nil {
+		t.Fatalf("IngestTelemetry with --resume returned error: %v", err)
+	}
+	if lines := sentTraceLines(mock); lines != "3,4,5,6" {
+		t.Errorf("Expected the failed line and those after it to be sent again, got %q", lines)
+	}
+}
+
+func TestIngestTelemetryCheckpointPassesDeadLetteredLines(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	mock.ShouldFail = true
+	content := traceLines(5)
+	input := writeInputFile(t, "capture.jsonl", []byte(content))
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	cfg := checkpointConfig(mock, statePath, false)
+	cfg.DeadLetterPath = filepath.Join(t.TempDir(), "dead.jsonl")
+	if err := IngestTelemetry(context.Background(), input, cfg); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	if entry := loadCheckpoint(t, statePath, input); entry.Line != 5 || entry.Offset != int64(len(content)) {
+		t.Errorf("Expected dead-lettered lines to count as handled, got line %d, offset %d", entry.Line, entry.Offset)
+	}
+}
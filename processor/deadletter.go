@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/deadletter"
+	"github.com/laiambryant/telemetry-ingestor/sender"
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// openDeadLetter opens the dead-letter file configured in cfg, or returns nil when there
+// is none or nothing is being sent
+func openDeadLetter(cfg *config.Config) (*deadletter.Writer, error) {
+	if cfg.DeadLetterPath == "" || cfg.DryRun {
+		return nil, nil
+	}
+	return deadletter.Open(cfg.DeadLetterPath)
+}
+
+// closeDeadLetter closes the dead-letter file and reports how many payloads were written to it
+func closeDeadLetter(w *deadletter.Writer) {
+	if w == nil {
+		return
+	}
+	if count := w.Count(); count > 0 {
+		slog.Warn("Wrote undeliverable telemetry to the dead-letter file", "path", w.Path(), "records", count)
+	}
+	if err := w.Close(); err != nil {
+		slog.Error("Failed to close the dead-letter file", "path", w.Path(), "error", err)
+	}
+}
+
+// writeDeadLetter records every part of job that failed to send with err. It reports
+// whether all of them were written, which is never the case without a dead-letter file.
+func writeDeadLetter(w *deadletter.Writer, job s.TelemetryJob, err error) bool {
+	if w == nil {
+		return false
+	}
+	written := true
+	for _, failed := range sender.FailedJobs(job, err) {
+		if werr := w.Write(failed.Job, sender.StatusCode(failed.Err), failed.Err); werr != nil {
+			slog.Error("Failed to write dead letter", "type", failed.Job.TelemetryType, "line", failed.Job.LineNum, "error", werr)
+			written = false
+		}
+	}
+	return written
+}
+
+// ReplayDeadLetter sends every payload of a dead-letter file again in send-all mode.
+// Each payload goes to the endpoint recorded with it, so destinations that accepted it
+// during a fan-out run do not receive it twice, unless cfg.ConfiguredEndpoints sends it
+// to the configured endpoints instead. Payloads that fail once more go to the configured
+// dead-letter file, which therefore has to be a different file.
+func ReplayDeadLetter(ctx context.Context, filePath string, cfg *config.Config) error {
+	if cfg.DeadLetterPath != "" && samePath(cfg.DeadLetterPath, filePath) {
+		return &config.InvalidOptionError{Option: "dead-letter", Value: cfg.DeadLetterPath}
+	}
+	replayCfg := *cfg
+	replayCfg.SendAll = true
+	replayCfg.RecordedEndpoints = !cfg.ConfiguredEndpoints
+	slog.Info("Replaying dead-letter file", "file", filePath)
+	return IngestTelemetry(ctx, filePath, &replayCfg)
+}
+
+// recordedEndpoint returns the endpoint a dead-lettered line failed on, or an empty string
+// when the line carries no dead-letter metadata
+func recordedEndpoint(data s.TelemetryData) string {
+	metadata, _ := data[deadletter.MetadataField].(map[string]any)
+	endpoint, _ := metadata["endpoint"].(string)
+	return endpoint
+}
+
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
@@ -0,0 +1,215 @@
+package processor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/deadletter"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+func readDeadLetters(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open dead-letter file: %v", err)
+	}
+	defer file.Close()
+	var records []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("dead-letter line is not valid JSON: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestIngestTelemetryWritesDeadLetters(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	mock.ShouldFail = true
+	tmpPath, err := createTempTestFile(`{"resourceSpans":[{"scopeSpans":[]}]}
+{"resourceLogs":[{"scopeLogs":[]}]}`, "test-dead-letter-*.json")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+	deadLetterPath := filepath.Join(t.TempDir(), "dead.jsonl")
+	cfg := &config.Config{
+		OtelEndpoint:      mock.TracesURL(),
+		OtelLogsEndpoint:  mock.LogsURL(),
+		MaxBufferCapacity: 1048576,
+		SendAll:           true,
+		Workers:           1,
+		DeadLetterPath:    deadLetterPath,
+	}
+	if err := IngestTelemetry(context.Background(), tmpPath, cfg); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+
+	records := readDeadLetters(t, deadLetterPath)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %d", len(records))
+	}
+	lines := map[float64]map[string]any{}
+	for _, record := range records {
+		meta, _ := record[deadletter.MetadataField].(map[string]any)
+		if meta["statusCode"] != float64(500) {
+			t.Errorf("Expected status code 500, got %v", meta["statusCode"])
+		}
+		line, _ := meta["line"].(float64)
+		lines[line] = record
+	}
+	if _, ok := lines[1]["resourceSpans"]; !ok {
+		t.Errorf("Expected line 1 to hold the trace payload, got %v", lines[1])
+	}
+	if _, ok := lines[2]["resourceLogs"]; !ok {
+		t.Errorf("Expected line 2 to hold the log payload, got %v", lines[2])
+	}
+
+	mock.Reset()
+	replayCfg := *cfg
+	replayCfg.DeadLetterPath = ""
+	if err := ReplayDeadLetter(context.Background(), deadLetterPath, &replayCfg); err != nil {
+		t.Fatalf("ReplayDeadLetter returned error: %v", err)
+	}
+	traces, logs, _, _ := mock.GetStats()
+	if traces != 1 || logs != 1 {
+		t.Errorf("Expected the replay to resend 1 trace and 1 log payload, got traces=%d logs=%d", traces, logs)
+	}
+	if len(mock.ReceivedTraces) == 1 {
+		if _, ok := mock.ReceivedTraces[0][deadletter.MetadataField]; ok {
+			t.Error("Expected the dead-letter metadata not to be sent to the collector")
+		}
+	}
+}
+
+func TestReplayDeadLetterRoutesToRecordedEndpoint(t *testing.T) {
+	healthy := testutil.NewMockOTelCollector()
+	defer healthy.Close()
+	failing := testutil.NewMockOTelCollector()
+	defer failing.Close()
+	failing.ShouldFail = true
+	tmpPath, err := createTempTestFile(traceLines(2), "test-dead-letter-fan-out-*.json")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+	deadLetterPath := filepath.Join(t.TempDir(), "dead.jsonl")
+	cfg := &config.Config{
+		OtelEndpoint:      healthy.TracesURL() + "," + failing.TracesURL(),
+		MaxBufferCapacity: 1048576,
+		SendAll:           true,
+		Workers:           1,
+		DeadLetterPath:    deadLetterPath,
+	}
+	if err := IngestTelemetry(context.Background(), tmpPath, cfg); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	if records := readDeadLetters(t, deadLetterPath); len(records) != 2 {
+		t.Fatalf("Expected 2 dead letters from the failing destination, got %d", len(records))
+	}
+
+	for _, tc := range []struct {
+		name                string
+		configuredEndpoints bool
+		healthyTraces       int
+	}{
+		{name: "recorded endpoint", healthyTraces: 0},
+		{name: "configured endpoints", configuredEndpoints: true, healthyTraces: 2},
+	} {
+		healthy.Reset()
+		failing.Reset()
+		replayCfg := *cfg
+		replayCfg.DeadLetterPath = ""
+		replayCfg.ConfiguredEndpoints = tc.configuredEndpoints
+		if err := ReplayDeadLetter(context.Background(), deadLetterPath, &replayCfg); err != nil {
+			t.Fatalf("%s: ReplayDeadLetter returned error: %v", tc.name, err)
+		}
+		if traces, _, _, _ := failing.GetStats(); traces != 2 {
+			t.Errorf("%s: expected the failed destination to receive both payloads again, got %d", tc.name, traces)
+		}
+		if traces, _, _, _ := healthy.GetStats(); traces != tc.healthyTraces {
+			t.Errorf("%s: expected the destination that accepted the payloads to receive %d, got %d", tc.name, tc.healthyTraces, traces)
+		}
+	}
+}
+
+func TestIngestTelemetryLastModeWritesDeadLetters(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	mock.ShouldFail = true
+	tmpPath, err := createTempTestFile(`{"resourceMetrics":[{"scopeMetrics":[]}]}`, "test-dead-letter-last-*.json")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+	deadLetterPath := filepath.Join(t.TempDir(), "dead.jsonl")
+	cfg := &config.Config{
+		OtelMetricsEndpoint: mock.MetricsURL(),
+		MaxBufferCapacity:   1048576,
+		DeadLetterPath:      deadLetterPath,
+	}
+	if err := IngestTelemetry(context.Background(), tmpPath, cfg); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	if records := readDeadLetters(t, deadLetterPath); len(records) != 1 {
+		t.Errorf("Expected 1 dead letter, got %d", len(records))
+	}
+}
+
+func TestReplayDeadLetterRejectsSameFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	err := ReplayDeadLetter(context.Background(), path, &config.Config{DeadLetterPath: path})
+	var optionErr *config.InvalidOptionError
+	if !errors.As(err, &optionErr) || optionErr.Option != "dead-letter" {
+		t.Errorf("Expected InvalidOptionError for dead-letter, got %T: %v", err, err)
+	}
+}
+
+func TestIngestTelemetrySpillsShortCircuitedJobs(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	mock.ShouldFail = true
+	line := `{"resourceSpans":[{"scopeSpans":[]}]}`
+	tmpPath, err := createTempTestFile(strings.Repeat(line+"\n", 4), "test-breaker-*.json")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+	deadLetterPath := filepath.Join(t.TempDir(), "dead.jsonl")
+	cfg := &config.Config{
+		OtelEndpoint:      mock.TracesURL(),
+		MaxBufferCapacity: 1048576,
+		SendAll:           true,
+		Workers:           1,
+		DeadLetterPath:    deadLetterPath,
+		BreakerThreshold:  2,
+		BreakerCooldown:   time.Minute,
+	}
+	if err := IngestTelemetry(context.Background(), tmpPath, cfg); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	if _, _, _, total := mock.GetStats(); total != 2 {
+		t.Errorf("Expected the open breaker to stop requests after 2 failures, got %d", total)
+	}
+	records := readDeadLetters(t, deadLetterPath)
+	if len(records) != 4 {
+		t.Fatalf("Expected all 4 lines in the dead-letter file, got %d", len(records))
+	}
+	meta, _ := records[3][deadletter.MetadataField].(map[string]any)
+	if errMsg, _ := meta["error"].(string); !strings.Contains(errMsg, "circuit breaker") {
+		t.Errorf("Expected the last record to be short-circuited, got error %q", errMsg)
+	}
+}
@@ -0,0 +1,113 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Compression formats recognised in input files
+const (
+	compressionGzip  = "gzip"
+	compressionZstd  = "zstd"
+	compressionBzip2 = "bzip2"
+	compressionXZ    = "xz"
+)
+
+// compressionMagic maps the leading bytes of each compressed stream to its format
+var compressionMagic = []struct {
+	magic  []byte
+	format string
+}{
+	{[]byte{0x1f, 0x8b}, compressionGzip},
+	{[]byte{0x28, 0xb5, 0x2f, 0xfd}, compressionZstd},
+	{[]byte("BZh"), compressionBzip2},
+	{[]byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, compressionXZ},
+}
+
+// compressionExtensions maps file extensions to the format they name
+var compressionExtensions = map[string]string{
+	".gz":   compressionGzip,
+	".gzip": compressionGzip,
+	".zst":  compressionZstd,
+	".zstd": compressionZstd,
+	".bz2":  compressionBzip2,
+	".xz":   compressionXZ,
+}
+
+// detectCompression returns the compression format of a stream starting with header,
+// falling back to the extension of name when the header is not recognised. An empty
+// format means the stream is read as is.
+func detectCompression(header []byte, name string) string {
+	for _, m := range compressionMagic {
+		if bytes.HasPrefix(header, m.magic) {
+			return m.format
+		}
+	}
+	return compressionExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// decompressedReader reads the decoded stream and closes both the decoder and the input
+type decompressedReader struct {
+	io.Reader
+	closeDecoder func()
+	input        io.Closer
+}
+
+func (d *decompressedReader) Close() error {
+	if d.closeDecoder != nil {
+		d.closeDecoder()
+	}
+	return d.input.Close()
+}
+
+// decompress wraps input in a decoder when it is compressed, reading ahead only as
+// far as the longest magic number so that pipes need no temporary copy
+func decompress(input io.ReadCloser, name string) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(input)
+	header, err := buffered.Peek(6)
+	if err != nil && err != io.EOF {
+		return nil, &FileReadError{FilePath: name, Err: err}
+	}
+
+	format := detectCompression(header, name)
+	reader := &decompressedReader{input: input}
+	switch format {
+	case compressionGzip:
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, &DecompressError{FilePath: name, Format: format, Err: err}
+		}
+		reader.Reader, reader.closeDecoder = gz, func() { gz.Close() }
+	case compressionZstd:
+		decoder, err := zstd.NewReader(buffered, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, &DecompressError{FilePath: name, Format: format, Err: err}
+		}
+		reader.Reader, reader.closeDecoder = decoder, decoder.Close
+	case compressionBzip2:
+		reader.Reader = bzip2.NewReader(buffered)
+	case compressionXZ:
+		decoder, err := xz.NewReader(buffered)
+		if err != nil {
+			return nil, &DecompressError{FilePath: name, Format: format, Err: err}
+		}
+		reader.Reader = decoder
+	default:
+		// Plain files are returned unwrapped so that they can be seeked when resuming
+		if seeker, ok := input.(io.Seeker); ok {
+			if _, err := seeker.Seek(0, io.SeekStart); err == nil {
+				return input, nil
+			}
+		}
+		reader.Reader = buffered
+	}
+	return reader, nil
+}
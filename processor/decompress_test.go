@@ -0,0 +1,146 @@
+package processor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	c "github.com/laiambryant/gotestutils/ctesting"
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+	"github.com/ulikunitz/xz"
+)
+
+const compressedContent = `{"resourceSpans":[{"scopeSpans":[]}]}
+{"resourceLogs":[{"scopeLogs":[]}]}
+`
+
+// bzip2Content is compressedContent compressed with bzip2, which the standard library can only decode
+var bzip2Content = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x33, 0xe7, 0xb9, 0xc6, 0x00, 0x00,
+	0x21, 0xdf, 0x80, 0x00, 0x10, 0x10, 0x00, 0x00, 0x10, 0x00, 0x04, 0x08, 0x0a, 0x2a, 0x81, 0xda,
+	0x0a, 0x20, 0x00, 0x40, 0x55, 0x53, 0x26, 0x20, 0x32, 0x66, 0xa1, 0x4c, 0x98, 0x99, 0x06, 0x46,
+	0x43, 0x24, 0x14, 0x3d, 0x2c, 0x55, 0xd3, 0x2d, 0x39, 0x4f, 0x12, 0x96, 0xed, 0x3e, 0x5d, 0x45,
+	0x98, 0x55, 0x0b, 0xa2, 0x12, 0x9b, 0xe5, 0x5a, 0x61, 0x07, 0xe2, 0xee, 0x48, 0xa7, 0x0a, 0x12,
+	0x06, 0x7c, 0xf7, 0x38, 0xc0,
+}
+
+func compressContent(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch format {
+	case compressionGzip:
+		w = gzip.NewWriter(&buf)
+	case compressionZstd:
+		w, err = zstd.NewWriter(&buf)
+	case compressionXZ:
+		w, err = xz.NewWriter(&buf)
+	case compressionBzip2:
+		return bzip2Content
+	default:
+		return []byte(compressedContent)
+	}
+	if err != nil {
+		t.Fatalf("failed to create %s writer: %v", format, err)
+	}
+	if _, err := w.Write([]byte(compressedContent)); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func writeInputFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func createDecompressTest(t *testing.T, name, format string) c.CharacterizationTest[string] {
+	path := writeInputFile(t, name, compressContent(t, format))
+	return c.NewCharacterizationTest(compressedContent, nil, func() (string, error) {
+		input, err := OpenInput(path)
+		if err != nil {
+			return "", err
+		}
+		defer input.Close()
+		data, err := io.ReadAll(input)
+		return string(data), err
+	})
+}
+
+func TestOpenInputDecompresses(t *testing.T) {
+	tests := []c.CharacterizationTest[string]{
+		createDecompressTest(t, "capture.jsonl", ""),
+		createDecompressTest(t, "capture.jsonl.gz", compressionGzip),
+		createDecompressTest(t, "capture.jsonl.zst", compressionZstd),
+		createDecompressTest(t, "capture.jsonl.bz2", compressionBzip2),
+		createDecompressTest(t, "capture.jsonl.xz", compressionXZ),
+		createDecompressTest(t, "gzip-without-extension", compressionGzip),
+		createDecompressTest(t, "zstd-without-extension", compressionZstd),
+		createDecompressTest(t, "bzip2-without-extension", compressionBzip2),
+		createDecompressTest(t, "xz-without-extension", compressionXZ),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestDetectCompression(t *testing.T) {
+	tests := []c.CharacterizationTest[string]{
+		c.NewCharacterizationTest(compressionGzip, nil, func() (string, error) {
+			return detectCompression([]byte{0x1f, 0x8b, 0x08}, "capture.jsonl"), nil
+		}),
+		c.NewCharacterizationTest(compressionZstd, nil, func() (string, error) {
+			return detectCompression([]byte(`{"res`), "capture.JSONL.ZST"), nil
+		}),
+		c.NewCharacterizationTest(compressionXZ, nil, func() (string, error) {
+			return detectCompression([]byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, "capture.gz"), nil
+		}),
+		c.NewCharacterizationTest("", nil, func() (string, error) {
+			return detectCompression(nil, "capture.jsonl"), nil
+		}),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestOpenInputRejectsCorruptCompressedFile(t *testing.T) {
+	path := writeInputFile(t, "capture.jsonl.gz", []byte(compressedContent))
+	_, err := OpenInput(path)
+	var de *DecompressError
+	if !errors.As(err, &de) || de.Format != compressionGzip || de.FilePath != path {
+		t.Errorf("Expected gzip DecompressError for %s, got %T: %v", path, err, err)
+	}
+}
+
+func TestIngestTelemetryCompressedFile(t *testing.T) {
+	for _, sendAll := range []bool{false, true} {
+		mock := testutil.NewMockOTelCollector()
+		path := writeInputFile(t, "capture.jsonl.zst", compressContent(t, compressionZstd))
+		cfg := &config.Config{
+			OtelEndpoint:      mock.TracesURL(),
+			OtelLogsEndpoint:  mock.LogsURL(),
+			MaxBufferCapacity: 1048576,
+			SendAll:           sendAll,
+			Workers:           2,
+		}
+		if err := IngestTelemetry(context.Background(), path, cfg); err != nil {
+			t.Errorf("IngestTelemetry(sendAll=%v) returned error: %v", sendAll, err)
+		}
+		if traces, logs, _, _ := mock.GetStats(); traces != 1 || logs != 1 {
+			t.Errorf("sendAll=%v: expected traces=1 logs=1, got traces=%d logs=%d", sendAll, traces, logs)
+		}
+		mock.Close()
+	}
+}
@@ -0,0 +1,202 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// defaultFollowInterval is used when no poll interval is configured
+const defaultFollowInterval = time.Second
+
+// FollowReader reads a file and keeps reading past its end as it grows, like tail -F.
+// At the end of the file it polls for new data, reopening the path when the file was
+// rotated (replaced by a file with another identity) and starting over when it was
+// truncated. Only complete lines are returned: a line the writer has not finished yet is
+// held back until its newline arrives, and dropped once ctx is cancelled, when the reader
+// reports io.EOF so readers finish cleanly.
+type FollowReader struct {
+	ctx      context.Context
+	path     string
+	interval time.Duration
+	file     *os.File
+	info     os.FileInfo
+	offset   int64
+	// maxHeld bounds the unfinished line held back, 0 leaves it unbounded
+	maxHeld int
+	buf     []byte
+	ready   []byte
+	held    []byte
+}
+
+// NewFollowReader opens the file at path for following, reading it from the start
+func NewFollowReader(ctx context.Context, path string, interval time.Duration) (*FollowReader, error) {
+	if interval <= 0 {
+		interval = defaultFollowInterval
+	}
+	fr := &FollowReader{ctx: ctx, path: path, interval: interval}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, &FileNotFoundError{FilePath: path}
+	}
+	if err := fr.open(); err != nil {
+		return nil, &FileOpenError{FilePath: path, Err: err}
+	}
+	return fr, nil
+}
+
+// followInput opens the input at filePath for --follow, which needs an uncompressed
+// file on disk. An unfinished line longer than maxLine is passed on rather than held
+// back, so that the scanner reports it as too long.
+func followInput(ctx context.Context, filePath string, interval time.Duration, maxLine int) (io.ReadCloser, error) {
+	if filePath == StdinPath {
+		return nil, &FollowInputError{Reason: "standard input is not a file"}
+	}
+	fr, err := NewFollowReader(ctx, filePath, interval)
+	if err != nil {
+		return nil, err
+	}
+	fr.maxHeld = maxLine
+	header := make([]byte, 6)
+	n, err := io.ReadFull(fr.file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		fr.Close()
+		return nil, &FileReadError{FilePath: filePath, Err: err}
+	}
+	if format := detectCompression(header[:n], filePath); format != "" {
+		fr.Close()
+		return nil, &FollowInputError{Reason: fmt.Sprintf("%s is %s compressed", filePath, format)}
+	}
+	if _, err := fr.file.Seek(0, io.SeekStart); err != nil {
+		fr.Close()
+		return nil, &FileReadError{FilePath: filePath, Err: err}
+	}
+	return fr, nil
+}
+
+// Read reads new lines from the followed file, waiting for them at the end of the file
+func (fr *FollowReader) Read(p []byte) (int, error) {
+	for {
+		if len(fr.ready) > 0 {
+			n := copy(p, fr.ready)
+			fr.ready = fr.ready[n:]
+			return n, nil
+		}
+		if fr.buf == nil {
+			fr.buf = make([]byte, 32*1024)
+		}
+		n, err := fr.file.Read(fr.buf)
+		fr.offset += int64(n)
+		if n > 0 {
+			fr.hold(fr.buf[:n])
+			continue
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if fr.ctx.Err() != nil {
+			fr.dropHeld()
+			return 0, io.EOF
+		}
+		reopened, err := fr.checkReplaced()
+		if err != nil {
+			return 0, err
+		}
+		if reopened {
+			continue
+		}
+		select {
+		case <-fr.ctx.Done():
+			fr.dropHeld()
+			return 0, io.EOF
+		case <-time.After(fr.interval):
+		}
+	}
+}
+
+// hold appends data to the unfinished line and moves every complete line to the data
+// ready to be returned
+func (fr *FollowReader) hold(data []byte) {
+	end := 0
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		end = len(fr.held) + i + 1
+	}
+	fr.held = append(fr.held, data...)
+	if fr.maxHeld > 0 && len(fr.held)-end > fr.maxHeld {
+		end = len(fr.held)
+	}
+	fr.ready = append(fr.ready[:0], fr.held[:end]...)
+	fr.held = append(fr.held[:0], fr.held[end:]...)
+}
+
+// endHeld terminates the unfinished line, whose file will not grow any more after a
+// rotation or truncation, so that it is not joined to the first line read next
+func (fr *FollowReader) endHeld() {
+	if len(fr.held) > 0 {
+		fr.hold([]byte{'\n'})
+	}
+}
+
+// dropHeld discards the unfinished line when following stops before the writer ended it
+func (fr *FollowReader) dropHeld() {
+	if len(fr.held) > 0 {
+		slog.Warn("Dropping unfinished last line of followed file", "file", fr.path, "bytes", len(fr.held))
+		fr.held = nil
+	}
+}
+
+// checkReplaced reopens or rewinds the file when it was rotated or truncated and
+// reports whether there may be new data to read. A missing path is waited for, as
+// rotation may briefly leave no file behind.
+func (fr *FollowReader) checkReplaced() (bool, error) {
+	info, err := os.Stat(fr.path)
+	if err != nil {
+		return false, nil
+	}
+	if !os.SameFile(fr.info, info) {
+		// Lines written to the old file just before it was rotated are read first
+		if current, err := fr.file.Stat(); err == nil && current.Size() > fr.offset {
+			return true, nil
+		}
+		slog.Info("Followed file was rotated, reopening", "file", fr.path)
+		fr.endHeld()
+		fr.file.Close()
+		if err := fr.open(); err != nil {
+			return false, &FileOpenError{FilePath: fr.path, Err: err}
+		}
+		return true, nil
+	}
+	if info.Size() < fr.offset {
+		slog.Info("Followed file was truncated, reading from the start", "file", fr.path, "size", info.Size(), "offset", fr.offset)
+		if _, err := fr.file.Seek(0, io.SeekStart); err != nil {
+			return false, &FileReadError{FilePath: fr.path, Err: err}
+		}
+		fr.endHeld()
+		fr.offset = 0
+		return true, nil
+	}
+	return false, nil
+}
+
+// open opens the followed path and records the identity of the file it found
+func (fr *FollowReader) open() error {
+	file, err := os.Open(fr.path)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	fr.file, fr.info, fr.offset = file, info, 0
+	return nil
+}
+
+// Close closes the followed file
+func (fr *FollowReader) Close() error {
+	return fr.file.Close()
+}
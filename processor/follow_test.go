@@ -0,0 +1,194 @@
+package processor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+func appendToFile(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("failed to append to %s: %v", path, err)
+	}
+}
+
+func expectFollowedLine(t *testing.T, lines <-chan string, want string) {
+	t.Helper()
+	select {
+	case got, ok := <-lines:
+		if !ok {
+			t.Fatalf("Expected line %q, reader stopped", want)
+		}
+		if got != want {
+			t.Fatalf("Expected line %q, got %q", want, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for line %q", want)
+	}
+}
+
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFollowReaderHandlesTruncationAndRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collector.jsonl")
+	appendToFile(t, path, "first\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fr, err := NewFollowReader(ctx, path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewFollowReader returned error: %v", err)
+	}
+	defer fr.Close()
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(fr)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	expectFollowedLine(t, lines, "first")
+	appendToFile(t, path, "appended\n")
+	expectFollowedLine(t, lines, "appended")
+
+	if err := os.WriteFile(path, []byte("short\n"), 0o644); err != nil {
+		t.Fatalf("failed to truncate: %v", err)
+	}
+	expectFollowedLine(t, lines, "short")
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("failed to rotate: %v", err)
+	}
+	appendToFile(t, path+".1", "late write to the rotated file\n")
+	appendToFile(t, path, "rotated\n")
+	expectFollowedLine(t, lines, "late write to the rotated file")
+	expectFollowedLine(t, lines, "rotated")
+
+	cancel()
+	select {
+	case line, ok := <-lines:
+		if ok {
+			t.Errorf("Expected the reader to stop after cancellation, got line %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Timed out waiting for the reader to stop after cancellation")
+	}
+}
+
+func TestFollowReaderHoldsBackUnfinishedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collector.jsonl")
+	appendToFile(t, path, "first\nsec")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fr, err := NewFollowReader(ctx, path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewFollowReader returned error: %v", err)
+	}
+	defer fr.Close()
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(fr)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	expectFollowedLine(t, lines, "first")
+	appendToFile(t, path, "ond\nunfinished")
+	expectFollowedLine(t, lines, "second")
+
+	// Truncation ends the held line, the writer has moved on
+	if err := os.WriteFile(path, []byte("short\nhalf-writ"), 0o644); err != nil {
+		t.Fatalf("failed to truncate: %v", err)
+	}
+	expectFollowedLine(t, lines, "unfinished")
+	expectFollowedLine(t, lines, "short")
+
+	cancel()
+	select {
+	case line, ok := <-lines:
+		if ok {
+			t.Errorf("Expected the unfinished line to be dropped on cancellation, got line %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Timed out waiting for the reader to stop after cancellation")
+	}
+}
+
+func TestIngestTelemetryFollow(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	path := filepath.Join(t.TempDir(), "collector.jsonl")
+	appendToFile(t, path, `{"resourceSpans":[{"scopeSpans":[]}]}`+"\n")
+	cfg := &config.Config{
+		OtelEndpoint:      mock.TracesURL(),
+		OtelLogsEndpoint:  mock.LogsURL(),
+		MaxBufferCapacity: 1048576,
+		Workers:           2,
+		Follow:            true,
+		FollowInterval:    5 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- IngestTelemetry(ctx, path, cfg) }()
+
+	waitUntil(t, "the existing trace line", func() bool {
+		traces, _, _, _ := mock.GetStats()
+		return traces == 1
+	})
+	appendToFile(t, path, `{"resourceLogs":[{"scopeLogs":[]}]}`+"\n")
+	waitUntil(t, "the appended log line", func() bool {
+		_, logs, _, _ := mock.GetStats()
+		return logs == 1
+	})
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected interrupting --follow to end the run cleanly, got %T: %v", err, err)
+	}
+}
+
+func TestFollowRejectsUnfollowableInputs(t *testing.T) {
+	cfg := &config.Config{MaxBufferCapacity: 1048576, Follow: true}
+	var followErr *FollowInputError
+	if err := IngestTelemetry(context.Background(), StdinPath, cfg); !errors.As(err, &followErr) {
+		t.Errorf("Expected FollowInputError for standard input, got %T: %v", err, err)
+	}
+	if err := IngestTelemetryFiles(context.Background(), []string{"a.jsonl", "b.jsonl"}, cfg); !errors.As(err, &followErr) {
+		t.Errorf("Expected FollowInputError for several inputs, got %T: %v", err, err)
+	}
+	for _, name := range []string{"capture.jsonl.gz", "capture.jsonl"} {
+		path := writeInputFile(t, name, gzipped(t, traceLines(1)))
+		if err := IngestTelemetry(context.Background(), path, cfg); !errors.As(err, &followErr) {
+			t.Errorf("Expected FollowInputError for compressed %s, got %T: %v", name, err, err)
+		}
+	}
+}
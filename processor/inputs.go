@@ -0,0 +1,174 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+)
+
+// ExpandInputs turns input arguments into the list of files to ingest. Glob patterns are
+// expanded and directories are replaced by the files they contain, descending into
+// subdirectories when recursive is set. Hidden files are left out of both. Matches are
+// sorted and inputs keep the order they were given in, so runs are deterministic.
+// Arguments that match nothing are kept so that opening them reports the error for
+// that input. A directory without any visible file to ingest is an error, so that a
+// wrong path does not end in a run that processed nothing.
+func ExpandInputs(args []string, recursive bool) ([]string, error) {
+	var inputs []string
+	var errs []error
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			inputs = append(inputs, path)
+		}
+	}
+
+	for _, arg := range args {
+		matches := []string{arg}
+		if arg != StdinPath && strings.ContainsAny(arg, "*?[") {
+			if globbed := globVisible(arg); len(globbed) > 0 {
+				matches = globbed
+			}
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || !info.IsDir() {
+				add(match)
+				continue
+			}
+			files := directoryFiles(match, recursive)
+			if len(files) == 0 {
+				errs = append(errs, &NoInputFilesError{Dir: match, Recursive: recursive})
+			}
+			for _, file := range files {
+				add(file)
+			}
+		}
+	}
+	return inputs, errors.Join(errs...)
+}
+
+// globVisible expands pattern like a shell would, leaving out hidden files unless the
+// pattern itself names them
+func globVisible(pattern string) []string {
+	globbed, err := filepath.Glob(pattern)
+	if err != nil || strings.HasPrefix(filepath.Base(pattern), ".") {
+		return globbed
+	}
+	var matches []string
+	for _, match := range globbed {
+		if !strings.HasPrefix(filepath.Base(match), ".") {
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// directoryFiles returns the regular files in dir in lexical order
+func directoryFiles(dir string, recursive bool) []string {
+	var files []string
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			slog.Warn("Failed to read input directory", "path", path, "error", err)
+			return nil
+		}
+		if path == dir {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") || (entry.IsDir() && !recursive) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+// fileResult is the outcome of ingesting one input
+type fileResult struct {
+	path  string
+	stats *stats.SendStats
+	err   error
+}
+
+// IngestTelemetryFiles ingests several inputs one after the other. A file that fails is
+// reported and skipped unless cfg.FailFast is set, in which case its error ends the run.
+// An interruption always ends the run. Each file gets its own summary and, when there is
+// more than one input, a per-file breakdown is printed at the end.
+func IngestTelemetryFiles(ctx context.Context, inputs []string, cfg *config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if len(inputs) == 1 {
+		return ingestTelemetry(ctx, inputs[0], cfg)
+	}
+	if cfg.Follow {
+		return &FollowInputError{Reason: fmt.Sprintf("a single file can be followed, got %d inputs", len(inputs))}
+	}
+
+	// Transforms are shared so that every input is rewritten consistently
+	rewrite, err := newRewrite(ctx, cfg, inputs)
+	if err != nil {
+		return err
+	}
+
+	var results []fileResult
+	var errs []error
+	for _, input := range inputs {
+		result := fileResult{path: inputName(input), stats: &stats.SendStats{}}
+		result.err = ingestFile(ctx, input, cfg, result.stats, rewrite)
+		results = append(results, result)
+
+		var interrupted *InterruptedError
+		if errors.As(result.err, &interrupted) || ctx.Err() != nil {
+			printFileSummary(results)
+			return result.err
+		}
+		if result.err != nil {
+			slog.Error("Failed to ingest file", "file", result.path, "error", result.err)
+			if cfg.FailFast {
+				printFileSummary(results)
+				return result.err
+			}
+			errs = append(errs, result.err)
+		}
+	}
+
+	printFileSummary(results)
+	if len(errs) > 0 {
+		return &InputsFailedError{Failed: len(errs), Total: len(inputs), Err: errors.Join(errs...)}
+	}
+	return nil
+}
+
+// printFileSummary prints one line per ingested input
+func printFileSummary(results []fileResult) {
+	slog.Info("=== Per File Summary ===")
+	failedFiles := 0
+	for _, result := range results {
+		success, failed, bytes := result.stats.Totals()
+		if result.err != nil {
+			failedFiles++
+			slog.Info("File", "file", result.path, "success", success, "failed", failed, "bytes", bytes, "error", result.err)
+			continue
+		}
+		slog.Info("File", "file", result.path, "success", success, "failed", failed, "bytes", bytes)
+	}
+	slog.Info("Files", "processed", len(results), "failed", failedFiles)
+}
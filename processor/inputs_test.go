@@ -0,0 +1,128 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+func createInputTree(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"b.json", "a.json", "c.txt", ".hidden.json", "sub/d.json", "sub/deeper/e.json", ".git/f.json"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(`{"resourceSpans":[{"scopeSpans":[]}]}`+"\n"), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+	return dir
+}
+
+func relativeInputs(dir string, inputs []string) string {
+	rel := make([]string, len(inputs))
+	for i, input := range inputs {
+		if r, err := filepath.Rel(dir, input); err == nil {
+			input = filepath.ToSlash(r)
+		}
+		rel[i] = input
+	}
+	return strings.Join(rel, " ")
+}
+
+func TestExpandInputs(t *testing.T) {
+	dir := createInputTree(t)
+	expand := func(recursive bool, args ...string) func() (string, error) {
+		return func() (string, error) {
+			for i, arg := range args {
+				if arg != StdinPath {
+					args[i] = filepath.Join(dir, arg)
+				}
+			}
+			inputs, err := ExpandInputs(args, recursive)
+			return relativeInputs(dir, inputs), err
+		}
+	}
+	tests := []c.CharacterizationTest[string]{
+		c.NewCharacterizationTest("a.json b.json c.txt", nil, expand(false, ".")),
+		c.NewCharacterizationTest("a.json b.json c.txt sub/d.json sub/deeper/e.json", nil, expand(true, ".")),
+		c.NewCharacterizationTest("a.json b.json", nil, expand(false, "*.json")),
+		c.NewCharacterizationTest("c.txt a.json b.json", nil, expand(false, "c.txt", "*.json", "a.json")),
+		c.NewCharacterizationTest("sub/d.json sub/deeper/e.json", nil, expand(true, "su?")),
+		c.NewCharacterizationTest("missing.json *.yaml -", nil, expand(false, "missing.json", "*.yaml", "-")),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestExpandInputsRejectsDirectoriesWithoutFiles(t *testing.T) {
+	dir := createInputTree(t)
+	empty := filepath.Join(dir, "empty")
+	hiddenOnly := filepath.Join(dir, "hidden-only")
+	if err := os.MkdirAll(filepath.Join(hiddenOnly, ".cache"), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.Mkdir(empty, 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(hiddenOnly, ".state.json"), []byte("{}\n"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	for _, args := range [][]string{{empty}, {hiddenOnly}, {filepath.Join(dir, "a.json"), empty}} {
+		inputs, err := ExpandInputs(args, false)
+		var noFiles *NoInputFilesError
+		if !errors.As(err, &noFiles) {
+			t.Errorf("Expected NoInputFilesError for %v, got %v (inputs %v)", args, err, inputs)
+		}
+	}
+	if _, err := ExpandInputs([]string{filepath.Join(dir, "sub")}, false); err != nil {
+		t.Errorf("Expected sub to have a file at its top level, got %v", err)
+	}
+}
+
+func TestIngestTelemetryFilesReportsFailuresPerFile(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	dir := createInputTree(t)
+	inputs := []string{filepath.Join(dir, "a.json"), filepath.Join(dir, "missing.json"), filepath.Join(dir, "b.json")}
+	cfg := &config.Config{OtelEndpoint: mock.TracesURL(), MaxBufferCapacity: 1048576}
+
+	err := IngestTelemetryFiles(context.Background(), inputs, cfg)
+	var failed *InputsFailedError
+	if !errors.As(err, &failed) || failed.Failed != 1 || failed.Total != 3 {
+		t.Fatalf("Expected InputsFailedError for 1 of 3 inputs, got %T: %v", err, err)
+	}
+	var notFound *FileNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("Expected the FileNotFoundError of the missing input to be wrapped, got %v", err)
+	}
+	if traces, _, _, _ := mock.GetStats(); traces != 2 {
+		t.Errorf("Expected the inputs around the missing one to be sent, got %d trace requests", traces)
+	}
+}
+
+func TestIngestTelemetryFilesFailFast(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	dir := createInputTree(t)
+	inputs := []string{filepath.Join(dir, "a.json"), filepath.Join(dir, "missing.json"), filepath.Join(dir, "b.json")}
+	cfg := &config.Config{OtelEndpoint: mock.TracesURL(), MaxBufferCapacity: 1048576, FailFast: true}
+
+	err := IngestTelemetryFiles(context.Background(), inputs, cfg)
+	var notFound *FileNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected FileNotFoundError, got %T: %v", err, err)
+	}
+	if traces, _, _, _ := mock.GetStats(); traces != 1 {
+		t.Errorf("Expected the run to stop at the missing input, got %d trace requests", traces)
+	}
+}
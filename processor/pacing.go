@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/transform"
+)
+
+// pacer holds lines back so that they are sent with the spacing of their original
+// timestamps, divided by speed. Gaps longer than maxGap, measured after applying the
+// speed, are shortened to maxGap. Lines are scheduled on a replay clock that starts with
+// the first line carrying timestamps, so waits do not add up to drift.
+type pacer struct {
+	speed  float64
+	maxGap time.Duration
+
+	started bool
+	start   time.Time
+	newest  int64
+	elapsed time.Duration
+}
+
+// newPacer returns the pacer configured in cfg, or nil when pacing is off
+func newPacer(cfg *config.Config) *pacer {
+	if !cfg.ReplayPacing {
+		return nil
+	}
+	slog.Info("Pacing replay on original timestamps", "speed", cfg.ReplaySpeed, "max_gap", cfg.ReplayMaxGap)
+	return &pacer{speed: cfg.ReplaySpeed, maxGap: cfg.ReplayMaxGap}
+}
+
+// wait blocks until the line data is due and returns the error of ctx if it is cancelled
+// first. A line is timed by its oldest timestamp; lines without timestamps, and lines
+// older than one already sent, are due at once.
+func (p *pacer) wait(ctx context.Context, data s.TelemetryData) error {
+	if p == nil {
+		return nil
+	}
+	var r transform.TimestampRange
+	r.Observe(data)
+	if !r.Found {
+		return nil
+	}
+	due := p.schedule(r.Oldest)
+	if p.start.IsZero() {
+		p.start = time.Now()
+	}
+	delay := time.Until(p.start.Add(due))
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// schedule returns when the line with original timestamp ts is due, relative to the
+// first timed line
+func (p *pacer) schedule(ts int64) time.Duration {
+	if !p.started {
+		p.started = true
+		p.newest = ts
+		return 0
+	}
+	if ts > p.newest {
+		gap := time.Duration(float64(ts-p.newest) / p.speed)
+		if p.maxGap > 0 && gap > p.maxGap {
+			gap = p.maxGap
+		}
+		p.elapsed += gap
+		p.newest = ts
+	}
+	return p.elapsed
+}
@@ -0,0 +1,108 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+func TestPacerSchedule(t *testing.T) {
+	schedule := func(speed float64, maxGap time.Duration, timestamps ...int64) func() (string, error) {
+		return func() (string, error) {
+			p := &pacer{speed: speed, maxGap: maxGap}
+			var due []string
+			for _, ts := range timestamps {
+				due = append(due, p.schedule(ts).String())
+			}
+			return strings.Join(due, " "), nil
+		}
+	}
+	second := int64(time.Second)
+	tests := []c.CharacterizationTest[string]{
+		c.NewCharacterizationTest("0s 1s 3s", nil, schedule(1, 0, 10*second, 11*second, 13*second)),
+		c.NewCharacterizationTest("0s 100ms 300ms", nil, schedule(10, 0, 10*second, 11*second, 13*second)),
+		c.NewCharacterizationTest("0s 2s 4s", nil, schedule(0.5, 0, 10*second, 11*second, 12*second)),
+		// An idle hour is shortened to the maximum gap
+		c.NewCharacterizationTest("0s 1s 6s 7s", nil, schedule(1, 5*time.Second, 0, second, 3601*second, 3602*second)),
+		// Lines older than the newest one sent are due at once and do not move the clock back
+		c.NewCharacterizationTest("0s 2s 2s 3s", nil, schedule(1, 0, 10*second, 12*second, 11*second, 13*second)),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func pacedLog(ts time.Duration) string {
+	return fmt.Sprintf(`{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"timeUnixNano":"%d"}]}]}]}`, int64(ts))
+}
+
+func TestIngestTelemetryReplayPacing(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	// 1s and then 1h apart, replayed at 10x with idle gaps capped at 200ms
+	content := strings.Join([]string{pacedLog(time.Hour), `{"resourceLogs":[]}`, pacedLog(time.Hour + time.Second), pacedLog(2*time.Hour + time.Second)}, "\n")
+	path := writeInputFile(t, "capture.jsonl", []byte(content))
+	cfg := &config.Config{
+		OtelLogsEndpoint:  mock.LogsURL(),
+		MaxBufferCapacity: 1048576,
+		SendAll:           true,
+		Workers:           2,
+		ReplayPacing:      true,
+		ReplaySpeed:       10,
+		ReplayMaxGap:      200 * time.Millisecond,
+	}
+
+	start := time.Now()
+	if err := IngestTelemetry(context.Background(), path, cfg); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	elapsed := time.Since(start)
+	if elapsed < 300*time.Millisecond || elapsed > 3*time.Second {
+		t.Errorf("Expected the replay to take about 300ms, took %v", elapsed)
+	}
+	if _, logs, _, _ := mock.GetStats(); logs != 4 {
+		t.Errorf("Expected every line to be sent, got %d log requests", logs)
+	}
+}
+
+func TestReplayPacingStopsOnShutdown(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	path := writeInputFile(t, "capture.jsonl", []byte(pacedLog(time.Second)+"\n"+pacedLog(time.Hour)+"\n"))
+	cfg := &config.Config{
+		OtelLogsEndpoint:  mock.LogsURL(),
+		MaxBufferCapacity: 1048576,
+		SendAll:           true,
+		Workers:           1,
+		ReplayPacing:      true,
+		ReplaySpeed:       1,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- IngestTelemetry(ctx, path, cfg) }()
+	waitUntil(t, "the first line", func() bool {
+		_, logs, _, _ := mock.GetStats()
+		return logs == 1
+	})
+	cancel()
+
+	select {
+	case err := <-done:
+		var interrupted *InterruptedError
+		if !errors.As(err, &interrupted) || interrupted.LastHandledLine != 1 {
+			t.Errorf("Expected InterruptedError after line 1, got %T: %v", err, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected shutdown to end the wait for the next line")
+	}
+	if _, logs, _, _ := mock.GetStats(); logs != 1 {
+		t.Errorf("Expected the line held back to be left unsent, got %d log requests", logs)
+	}
+}
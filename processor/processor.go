@@ -2,21 +2,30 @@ package processor
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
 	"os"
 	"sync"
 
 	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/deadletter"
+	"github.com/laiambryant/telemetry-ingestor/exporter"
 	"github.com/laiambryant/telemetry-ingestor/sender"
 	"github.com/laiambryant/telemetry-ingestor/stats"
 	s "github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/transform"
 )
 
 type LastTelemetryData struct {
-	Traces  s.TelemetryData
-	Logs    s.TelemetryData
-	Metrics s.TelemetryData
+	Traces      s.TelemetryData
+	Logs        s.TelemetryData
+	Metrics     s.TelemetryData
+	TracesLine  int
+	LogsLine    int
+	MetricsLine int
 }
 
 const (
@@ -25,22 +34,57 @@ const (
 	resourceMetricsField = "resourceMetrics"
 )
 
-func OpenTelemetryFile(filePath string, maxBufferCapacity int) (*os.File, *bufio.Scanner, error) {
+// StdinPath is the input path that reads telemetry from standard input
+const StdinPath = "-"
 
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, nil, &FileNotFoundError{FilePath: filePath}
+// OpenTelemetryFile opens an input with OpenInput and returns a scanner over its lines
+func OpenTelemetryFile(filePath string, maxBufferCapacity int) (io.ReadCloser, *bufio.Scanner, error) {
+	input, err := OpenInput(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return input, NewTelemetryScanner(input, maxBufferCapacity), nil
+}
+
+// OpenInput opens the file at filePath for reading, or standard input for StdinPath.
+// Compressed inputs (gzip, zstd, bzip2 or xz, recognised by their magic bytes or file
+// extension) are decoded on the fly. Closing the returned reader leaves standard input open.
+func OpenInput(filePath string) (io.ReadCloser, error) {
+	var input io.ReadCloser = io.NopCloser(os.Stdin)
+	if filePath != StdinPath {
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			return nil, &FileNotFoundError{FilePath: filePath}
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, &FileOpenError{FilePath: filePath, Err: err}
+		}
+		input = file
 	}
 
-	file, err := os.Open(filePath)
+	reader, err := decompress(input, inputName(filePath))
 	if err != nil {
-		return nil, nil, &FileOpenError{FilePath: filePath, Err: err}
+		input.Close()
+		return nil, err
 	}
+	return reader, nil
+}
 
-	scanner := bufio.NewScanner(file)
+// NewTelemetryScanner returns a scanner over the lines of r accepting lines of up to maxBufferCapacity bytes
+func NewTelemetryScanner(r io.Reader, maxBufferCapacity int) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
 	buf := make([]byte, maxBufferCapacity)
 	scanner.Buffer(buf, maxBufferCapacity)
+	return scanner
+}
 
-	return file, scanner, nil
+// inputName returns the name used for an input path in logs and errors
+func inputName(filePath string) string {
+	if filePath == StdinPath {
+		return "stdin"
+	}
+	return filePath
 }
 
 func ParseTelemetryLine(line string, lineNum int) (s.TelemetryData, error) {
@@ -58,100 +102,219 @@ func ParseTelemetryLine(line string, lineNum int) (s.TelemetryData, error) {
 }
 
 func ProcessTelemetryInSendAllMode(data s.TelemetryData, lineNum int, config *config.Config, jobChan chan<- s.TelemetryJob) {
-	if _, hasTraces := data[resourceSpansField]; hasTraces {
-		payload := map[string]any{resourceSpansField: data[resourceSpansField]}
-		jobChan <- s.TelemetryJob{
-			Endpoint:      config.OtelEndpoint,
-			Payload:       payload,
-			TelemetryType: s.TelemetryTraces,
-			LineNum:       lineNum,
-		}
+	for _, job := range BuildTelemetryJobs(data, lineNum, config) {
+		jobChan <- job
 	}
+}
 
-	if _, hasLogs := data[resourceLogsField]; hasLogs {
-		payload := map[string]any{resourceLogsField: data[resourceLogsField]}
-		jobChan <- s.TelemetryJob{
-			Endpoint:      config.OtelLogsEndpoint,
-			Payload:       payload,
-			TelemetryType: s.TelemetryLogs,
-			LineNum:       lineNum,
+// BuildTelemetryJobs returns one job per telemetry type present in a parsed line and
+// destination configured for that type. With RecordedEndpoints, a dead-lettered line
+// only goes to the endpoint it failed on.
+func BuildTelemetryJobs(data s.TelemetryData, lineNum int, config *config.Config) []s.TelemetryJob {
+	endpoints := func(configured string) string {
+		if recorded := recordedEndpoint(data); config.RecordedEndpoints && recorded != "" {
+			return recorded
 		}
+		return configured
+	}
+	var jobs []s.TelemetryJob
+	if _, hasTraces := data[resourceSpansField]; hasTraces {
+		jobs = append(jobs, destinationJobs(config, endpoints(config.OtelEndpoint), data[resourceSpansField], s.TelemetryTraces, lineNum)...)
+	}
+	if _, hasLogs := data[resourceLogsField]; hasLogs {
+		jobs = append(jobs, destinationJobs(config, endpoints(config.OtelLogsEndpoint), data[resourceLogsField], s.TelemetryLogs, lineNum)...)
 	}
-
 	if _, hasMetrics := data[resourceMetricsField]; hasMetrics {
-		payload := map[string]any{resourceMetricsField: data[resourceMetricsField]}
-		jobChan <- s.TelemetryJob{
-			Endpoint:      config.OtelMetricsEndpoint,
-			Payload:       payload,
-			TelemetryType: s.TelemetryMetrics,
+		jobs = append(jobs, destinationJobs(config, endpoints(config.OtelMetricsEndpoint), data[resourceMetricsField], s.TelemetryMetrics, lineNum)...)
+	}
+	return jobs
+}
+
+// destinationJobs returns a job carrying the resources of one telemetry type for each
+// destination listed in endpoints. The file and stdout exporters are a single destination
+// of their own.
+func destinationJobs(cfg *config.Config, endpoints string, resources any, telemetryType s.TelemetryType, lineNum int) []s.TelemetryJob {
+	destinations := config.SplitEndpoints(endpoints)
+	switch cfg.Exporter {
+	case config.EXPORTER_FILE:
+		destinations = []string{cfg.ExportFile}
+	case config.EXPORTER_STDOUT:
+		destinations = []string{config.EXPORTER_STDOUT}
+	}
+	var jobs []s.TelemetryJob
+	for _, endpoint := range destinations {
+		jobs = append(jobs, s.TelemetryJob{
+			Endpoint:      endpoint,
+			Payload:       map[string]any{resourceField(telemetryType): resources},
+			TelemetryType: telemetryType,
 			LineNum:       lineNum,
-		}
+		})
 	}
+	return jobs
 }
 
-func UpdateLastTelemetryData(data s.TelemetryData, lastData *LastTelemetryData) {
+func UpdateLastTelemetryData(data s.TelemetryData, lineNum int, lastData *LastTelemetryData) {
 	if _, hasTraces := data[resourceSpansField]; hasTraces {
 		lastData.Traces = data
+		lastData.TracesLine = lineNum
 	}
 	if _, hasLogs := data[resourceLogsField]; hasLogs {
 		lastData.Logs = data
+		lastData.LogsLine = lineNum
 	}
 	if _, hasMetrics := data[resourceMetricsField]; hasMetrics {
 		lastData.Metrics = data
+		lastData.MetricsLine = lineNum
 	}
 }
 
-func StartWorkerPool(numWorkers int, stats *stats.SendStats) (chan s.TelemetryJob, *sync.WaitGroup) {
+// defaultQueueSize is used when no per-destination queue size is configured
+const defaultQueueSize = 1000
+
+// StartWorkerPool starts numWorkers workers for every destination, each pool created when
+// the first job for its endpoint arrives. Every pool reads from its own queue of up to
+// queueSize jobs, so a slow destination only holds up the others once it has fallen
+// queueSize jobs behind; sending on the returned channel then waits for it, which bounds
+// the memory a stalled destination can take. The returned channel accepts jobs for any
+// destination. The WaitGroup is done once the channel is closed and every pool has drained.
+func StartWorkerPool(ctx context.Context, numWorkers, queueSize int, exp exporter.Exporter, acks *AckTracker, deadLetters *deadletter.Writer) (chan s.TelemetryJob, *sync.WaitGroup) {
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
 	jobChan := make(chan s.TelemetryJob, numWorkers*2)
 	wg := &sync.WaitGroup{}
 
-	for i := range numWorkers {
-		wg.Add(1)
-		go worker(i+1, jobChan, wg, stats)
-	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		pools := make(map[string]chan s.TelemetryJob)
+		poolsWG := &sync.WaitGroup{}
+		workerID := 0
+		for job := range jobChan {
+			pool, ok := pools[job.Endpoint]
+			if !ok {
+				pool = make(chan s.TelemetryJob, queueSize)
+				pools[job.Endpoint] = pool
+				for range numWorkers {
+					workerID++
+					poolsWG.Add(1)
+					go worker(ctx, workerID, pool, poolsWG, exp, acks, deadLetters)
+				}
+			}
+			pool <- job
+		}
+		for _, pool := range pools {
+			close(pool)
+		}
+		poolsWG.Wait()
+	}()
 
 	return jobChan, wg
 }
 
-func ProcessFileInSendAllMode(scanner *bufio.Scanner, config *config.Config, stats *stats.SendStats) error {
-	jobChan, wg := StartWorkerPool(config.Workers, stats)
+func ProcessFileInSendAllMode(ctx context.Context, scanner *bufio.Scanner, config *config.Config, stats *stats.SendStats) error {
+	return processSendAll(ctx, scanner, config, stats, nil, nil)
+}
 
-	lineNum := 0
+// processSendAll sends every line read by scanner after rewriting it. With a checkpoint,
+// line numbers and offsets continue from the resumed position and progress is saved as
+// lines are acknowledged.
+func processSendAll(ctx context.Context, scanner *bufio.Scanner, config *config.Config, stats *stats.SendStats, progress *fileCheckpoint, rewrite transform.Chain) error {
+	exp, err := exporter.New(config, stats)
+	if err != nil {
+		return err
+	}
+	defer shutdownExporter(exp)
+	deadLetters, err := openDeadLetter(config)
+	if err != nil {
+		return err
+	}
+	defer closeDeadLetter(deadLetters)
+
+	// Sends outlive ctx by the grace period so queued jobs can drain after a shutdown request
+	sendCtx, cancelSends := withGracePeriod(ctx, config.ShutdownGracePeriod)
+	defer cancelSends()
+	lineNum, offset := progress.start()
+	acks := NewAckTrackerFrom(lineNum, offset)
+	if progress != nil {
+		countOffsets(scanner, &offset)
+	}
+	jobChan, wg := StartWorkerPool(sendCtx, config.Workers, config.QueueSize, exp, acks, deadLetters)
+	defer progress.track(acks)()
+
+	// With batching, lines go through the batcher, which closes jobChan once it has flushed
+	queue := jobChan
+	if config.Batch {
+		queue = make(chan s.TelemetryJob, config.Workers*2)
+		go NewBatcher(config, jobChan).Run(queue)
+	}
+
+	pacing := newPacer(config)
 	lineCount := 0
 
-	for scanner.Scan() {
+	for ctx.Err() == nil && scanner.Scan() {
 		lineNum++
 		line := scanner.Text()
 
 		data, err := ParseTelemetryLine(line, lineNum)
 		if err != nil || data == nil {
+			acks.AddLine(lineNum, 0, offset)
 			continue
 		}
 
+		rewrite.Apply(data)
+		// A line held back by pacing when shutdown is requested is left unsent
+		if err := pacing.wait(ctx, data); err != nil {
+			break
+		}
+
 		lineCount++
-		ProcessTelemetryInSendAllMode(data, lineNum, config, jobChan)
+		jobs := BuildTelemetryJobs(data, lineNum, config)
+		acks.AddLine(lineNum, len(jobs), offset)
+		for _, job := range jobs {
+			queue <- job
+		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return err
+	// Lines already queued are still delivered when reading fails
+	scanErr := scanner.Err()
+	if scanErr != nil {
+		slog.Error("Stopped reading file", "total_lines", lineCount, "error", scanErr)
+	} else if ctx.Err() != nil {
+		slog.Warn("Stopped reading file", "total_lines", lineCount)
+	} else {
+		slog.Info("Finished reading file", "total_lines", lineCount)
 	}
 
-	slog.Info("Finished reading file", "total_lines", lineCount)
-
-	close(jobChan)
+	close(queue)
 	slog.Info("Waiting for workers to finish")
 	wg.Wait()
-	stats.PrintSummary()
+	printSummary(config, stats)
+	if scanErr != nil {
+		return scanErr
+	}
 
+	lastHandled, failedLines := acks.LastHandled(), acks.Failed()
+	slog.Info("Last fully handled line", "line", lastHandled, "failed_lines", failedLines)
+	if err := ctx.Err(); err != nil {
+		return &InterruptedError{LastHandledLine: lastHandled, FailedLines: failedLines, Err: err}
+	}
 	return nil
 }
 
-func ProcessFileInLastMode(scanner *bufio.Scanner) (*LastTelemetryData, int, error) {
+func ProcessFileInLastMode(ctx context.Context, scanner *bufio.Scanner) (*LastTelemetryData, int, error) {
+	return processLastMode(ctx, scanner, nil)
+}
+
+// processLastMode keeps the last line of each telemetry type read by scanner. Every line
+// is rewritten, not only the ones kept, so transforms that carry state across lines see
+// the whole input.
+func processLastMode(ctx context.Context, scanner *bufio.Scanner, rewrite transform.Chain) (*LastTelemetryData, int, error) {
 	lastData := &LastTelemetryData{}
 	lineNum := 0
 	lineCount := 0
 
-	for scanner.Scan() {
+	for ctx.Err() == nil && scanner.Scan() {
 		lineNum++
 		line := scanner.Text()
 
@@ -161,84 +324,214 @@ func ProcessFileInLastMode(scanner *bufio.Scanner) (*LastTelemetryData, int, err
 		}
 
 		lineCount++
-		UpdateLastTelemetryData(data, lastData)
+		rewrite.Apply(data)
+		UpdateLastTelemetryData(data, lineNum, lastData)
 	}
 
 	if err := scanner.Err(); err != nil {
 		return nil, lineCount, err
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, lineCount, err
+	}
 
 	slog.Info("Finished reading file", "total_lines", lineCount)
 	return lastData, lineCount, nil
 }
 
-func SendLastTelemetryData(lastData *LastTelemetryData, config *config.Config, stats *stats.SendStats) {
+func SendLastTelemetryData(ctx context.Context, lastData *LastTelemetryData, config *config.Config, stats *stats.SendStats) error {
 	slog.Info("Sending last instances to OTel Collector")
+	exp, err := exporter.New(config, stats)
+	if err != nil {
+		return err
+	}
+	defer shutdownExporter(exp)
+	deadLetters, err := openDeadLetter(config)
+	if err != nil {
+		return err
+	}
+	defer closeDeadLetter(deadLetters)
 
-	if lastData.Traces != nil {
-		payload := map[string]any{
-			resourceSpansField: lastData.Traces[resourceSpansField],
-		}
-		if err := sender.SendToOTel(config.OtelEndpoint, payload, s.TelemetryTraces, stats); err != nil {
-			slog.Error("Failed to send traces", "error", err)
-		}
+	ctx, cancel := withGracePeriod(ctx, config.ShutdownGracePeriod)
+	defer cancel()
+
+	// Destinations are sent to concurrently so a slow one does not delay the others
+	var wg sync.WaitGroup
+	for _, job := range lastTelemetryJobs(lastData, config) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := exp.Export(ctx, job); err != nil {
+				logSendFailure("Failed to send telemetry", job, err)
+				if ctx.Err() == nil {
+					writeDeadLetter(deadLetters, job, err)
+				}
+			}
+		}()
 	}
+	wg.Wait()
 
+	printSummary(config, stats)
+	return nil
+}
+
+// lastTelemetryJobs returns a job for the last instance of each telemetry type found
+// and each destination configured for that type
+func lastTelemetryJobs(lastData *LastTelemetryData, config *config.Config) []s.TelemetryJob {
+	var jobs []s.TelemetryJob
+	if lastData.Traces != nil {
+		jobs = append(jobs, destinationJobs(config, config.OtelEndpoint, lastData.Traces[resourceSpansField], s.TelemetryTraces, lastData.TracesLine)...)
+	}
 	if lastData.Logs != nil {
-		payload := map[string]any{
-			resourceLogsField: lastData.Logs[resourceLogsField],
-		}
-		if err := sender.SendToOTel(config.OtelLogsEndpoint, payload, s.TelemetryLogs, stats); err != nil {
-			slog.Error("Failed to send logs", "error", err)
-		}
+		jobs = append(jobs, destinationJobs(config, config.OtelLogsEndpoint, lastData.Logs[resourceLogsField], s.TelemetryLogs, lastData.LogsLine)...)
 	}
-
 	if lastData.Metrics != nil {
-		payload := map[string]any{
-			resourceMetricsField: lastData.Metrics[resourceMetricsField],
-		}
-		if err := sender.SendToOTel(config.OtelMetricsEndpoint, payload, s.TelemetryMetrics, stats); err != nil {
-			slog.Error("Failed to send metrics", "error", err)
-		}
+		jobs = append(jobs, destinationJobs(config, config.OtelMetricsEndpoint, lastData.Metrics[resourceMetricsField], s.TelemetryMetrics, lastData.MetricsLine)...)
 	}
+	return jobs
+}
 
-	stats.PrintSummary()
+// IngestTelemetry reads telemetry from the file at filePath, or standard input for
+// StdinPath, and sends it as configured
+func IngestTelemetry(ctx context.Context, filePath string, cfg *config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	return ingestTelemetry(ctx, filePath, cfg)
 }
 
-func IngestTelemetry(filePath string, cfg *config.Config) error {
-	slog.Info("Reading telemetry data", "file", filePath)
-	if cfg.SendAll {
-		slog.Info("Mode: Sending all telemetry lines")
+// ingestTelemetry ingests a single input with a configuration that was already validated
+func ingestTelemetry(ctx context.Context, filePath string, cfg *config.Config) error {
+	rewrite, err := newRewrite(ctx, cfg, []string{filePath})
+	if err != nil {
+		return err
+	}
+	return ingestFile(ctx, filePath, cfg, &stats.SendStats{}, rewrite)
+}
+
+// ingestFile opens an input and ingests it, rewriting its lines with rewrite and recording on stats
+func ingestFile(ctx context.Context, filePath string, cfg *config.Config, stats *stats.SendStats, rewrite transform.Chain) error {
+	var input io.ReadCloser
+	var err error
+	if cfg.Follow {
+		input, err = followInput(ctx, filePath, cfg.FollowInterval, cfg.MaxBufferCapacity)
 	} else {
-		slog.Info("Mode: Scanning file to find last instances of each telemetry type")
+		input, err = OpenInput(filePath)
 	}
+	if err != nil {
+		return err
+	}
+	defer input.Close()
 
-	file, scanner, err := OpenTelemetryFile(filePath, cfg.MaxBufferCapacity)
+	progress, err := openCheckpoint(cfg, filePath)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	if err := progress.resume(input); err != nil {
+		return err
+	}
+	err = ingestReader(ctx, input, inputName(filePath), cfg, stats, progress, rewrite)
+	// Following only ends when the tool is interrupted, which is its normal way out
+	var interrupted *InterruptedError
+	if cfg.Follow && errors.As(err, &interrupted) {
+		slog.Info("Stopped following file", "file", filePath)
+		return nil
+	}
+	return err
+}
 
-	stats := &stats.SendStats{}
+func ingestReader(ctx context.Context, r io.Reader, name string, cfg *config.Config, stats *stats.SendStats, progress *fileCheckpoint, rewrite transform.Chain) error {
+	slog.Info("Reading telemetry data", "file", name)
+	// Following never reaches the end of the file, so only send-all mode applies
+	sendAll := cfg.SendAll || cfg.Follow
+	if cfg.Follow {
+		slog.Info("Mode: Following file and sending new telemetry lines")
+	} else if sendAll {
+		slog.Info("Mode: Sending all telemetry lines")
+	} else {
+		slog.Info("Mode: Scanning file to find last instances of each telemetry type")
+	}
+	if cfg.DryRun {
+		slog.Info("Dry run: telemetry is encoded and counted but not sent")
+	}
 
-	if cfg.SendAll {
-		return ProcessFileInSendAllMode(scanner, cfg, stats)
+	scanner := NewTelemetryScanner(r, cfg.MaxBufferCapacity)
+
+	if sendAll {
+		return processSendAll(ctx, scanner, cfg, stats, progress, rewrite)
 	}
 
-	lastData, _, err := ProcessFileInLastMode(scanner)
+	lastData, _, err := processLastMode(ctx, scanner, rewrite)
+	if ctx.Err() != nil {
+		return &InterruptedError{Err: ctx.Err()}
+	}
 	if err != nil {
-		return &FileReadError{FilePath: filePath, Err: err}
+		return &FileReadError{FilePath: name, Err: err}
 	}
 
-	SendLastTelemetryData(lastData, cfg, stats)
-	return nil
+	return SendLastTelemetryData(ctx, lastData, cfg, stats)
 }
 
-func worker(id int, jobs <-chan s.TelemetryJob, wg *sync.WaitGroup, stats *stats.SendStats) {
+// worker sends jobs until the queue is closed. Failed jobs are written to the dead-letter
+// file, if any, and only count as handled once they are. Once ctx is cancelled the
+// remaining jobs are drained without being sent and are left unacknowledged.
+func worker(ctx context.Context, id int, jobs <-chan s.TelemetryJob, wg *sync.WaitGroup, exp exporter.Exporter, acks *AckTracker, deadLetters *deadletter.Writer) {
 	defer wg.Done()
 	for job := range jobs {
-		if err := sender.SendToOTel(job.Endpoint, job.Payload, job.TelemetryType, stats); err != nil {
-			slog.Error("Worker failed to send telemetry", "worker", id, "type", job.TelemetryType, "line", job.LineNum, "error", err)
+		if ctx.Err() != nil {
+			continue
+		}
+		err := exp.Export(ctx, job)
+		if err != nil {
+			logSendFailure("Worker failed to send telemetry", job, err, "worker", id)
+			if ctx.Err() != nil {
+				continue
+			}
+			kept := writeDeadLetter(deadLetters, job, err)
+			for _, line := range sourceLines(acks, job) {
+				acks.Fail(line, kept)
+			}
+			continue
+		}
+		for _, line := range sourceLines(acks, job) {
+			acks.Done(line)
 		}
 	}
 }
+
+// sourceLines returns the source lines of job to report to acks, or none without a tracker
+func sourceLines(acks *AckTracker, job s.TelemetryJob) []int {
+	if acks == nil {
+		return nil
+	}
+	return job.SourceLines()
+}
+
+// printSummary prints the run summary, or the dry-run report when nothing was sent
+func printSummary(cfg *config.Config, stats *stats.SendStats) {
+	if cfg.DryRun {
+		stats.PrintDryRunReport()
+		return
+	}
+	stats.PrintSummary()
+}
+
+// shutdownExporter flushes and closes the exporter once every job has been handled
+func shutdownExporter(exp exporter.Exporter) {
+	if err := exp.Shutdown(context.Background()); err != nil {
+		slog.Error("Failed to shut down exporter", "error", err)
+	}
+}
+
+// logSendFailure logs a failed job. Senders return failures without logging them, so this
+// is the one place they are reported. Jobs failed fast by an open circuit breaker are only
+// logged at debug level, the breaker itself reports when it opens and closes.
+func logSendFailure(msg string, job s.TelemetryJob, err error, args ...any) {
+	args = append(args, "type", job.TelemetryType, "line", job.LineNum, "error", err)
+	var openErr *sender.CircuitOpenError
+	if errors.As(err, &openErr) {
+		slog.Debug(msg, args...)
+		return
+	}
+	slog.Error(msg, args...)
+}
@@ -35,3 +35,83 @@ func (e *FileReadError) Error() string {
 func (e *FileReadError) Unwrap() error {
 	return e.Err
 }
+
+// InterruptedError represents a run stopped by cancellation before the whole file was delivered.
+// Lines up to LastHandledLine were delivered or written to the dead-letter file, FailedLines
+// counts the lines with a job that failed.
+type InterruptedError struct {
+	LastHandledLine int
+	FailedLines     int
+	Err             error
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("ingestion interrupted, last fully handled line %d, %d failed lines: %v", e.LastHandledLine, e.FailedLines, e.Err)
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// InputsFailedError represents a run over several inputs in which some could not be ingested
+type InputsFailedError struct {
+	Failed int
+	Total  int
+	Err    error
+}
+
+func (e *InputsFailedError) Error() string {
+	return fmt.Sprintf("%d of %d inputs failed: %v", e.Failed, e.Total, e.Err)
+}
+
+func (e *InputsFailedError) Unwrap() error {
+	return e.Err
+}
+
+// DecompressError represents a compressed input whose stream could not be decoded
+type DecompressError struct {
+	FilePath string
+	Format   string
+	Err      error
+}
+
+func (e *DecompressError) Error() string {
+	return fmt.Sprintf("failed to decompress %s file %s: %v", e.Format, e.FilePath, e.Err)
+}
+
+func (e *DecompressError) Unwrap() error {
+	return e.Err
+}
+
+// FollowInputError represents inputs that cannot be followed with --follow
+type FollowInputError struct {
+	Reason string
+}
+
+func (e *FollowInputError) Error() string {
+	return fmt.Sprintf("cannot follow input: %s", e.Reason)
+}
+
+// SinglePassInputError represents an option that reads the input twice used with an
+// input that can only be read once
+type SinglePassInputError struct {
+	Option string
+	Input  string
+}
+
+func (e *SinglePassInputError) Error() string {
+	return fmt.Sprintf("option --%s reads the input twice and cannot be used with %s", e.Option, e.Input)
+}
+
+// NoInputFilesError represents a directory argument that contains no file to ingest
+type NoInputFilesError struct {
+	Dir       string
+	Recursive bool
+}
+
+func (e *NoInputFilesError) Error() string {
+	if e.Recursive {
+		return fmt.Sprintf("no input files found in directory %s", e.Dir)
+	}
+	return fmt.Sprintf("no input files found in directory %s, use --recursive to include subdirectories", e.Dir)
+}
@@ -2,11 +2,18 @@ package processor
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	c "github.com/laiambryant/gotestutils/ctesting"
 	"github.com/laiambryant/telemetry-ingestor/config"
@@ -18,6 +25,20 @@ type errReader struct{ err error }
 
 func (e *errReader) Read(p []byte) (int, error) { return 0, e.err }
 
+// endlessLines returns one trace line per Read until ctx is cancelled, counting them
+type endlessLines struct {
+	ctx   context.Context
+	lines atomic.Int64
+}
+
+func (e *endlessLines) Read(p []byte) (int, error) {
+	if e.ctx.Err() != nil {
+		return 0, io.EOF
+	}
+	e.lines.Add(1)
+	return copy(p, `{"resourceSpans":[{"scopeSpans":[]}]}`+"\n"), nil
+}
+
 type IngestResult struct {
 	TracesReceived  int
 	LogsReceived    int
@@ -60,7 +81,7 @@ func createIngestTest(fileContent string, sendAll bool, expected IngestResult) c
 				SendAll:             sendAll,
 				Workers:             2,
 			}
-			err = IngestTelemetry(tmpPath, cfg)
+			err = IngestTelemetry(context.Background(), tmpPath, cfg)
 			traces, logs, metrics, _ := mock.GetStats()
 			return IngestResult{
 				TracesReceived:  traces,
@@ -146,7 +167,7 @@ func TestIngestTelemetryFileNotFound(t *testing.T) {
 				SendAll:             false,
 				Workers:             2,
 			}
-			err := IngestTelemetry("/nonexistent/file.json", cfg)
+			err := IngestTelemetry(context.Background(), "/nonexistent/file.json", cfg)
 			return err != nil, nil
 		},
 	)
@@ -169,7 +190,7 @@ func TestIngestTelemetryFileReadError(t *testing.T) {
 		SendAll:             false,
 		Workers:             1,
 	}
-	err = IngestTelemetry(tmpPath, cfg)
+	err = IngestTelemetry(context.Background(), tmpPath, cfg)
 	if err == nil {
 		t.Fatalf("expected an error, got nil")
 	}
@@ -184,7 +205,7 @@ func TestProcessFileInSendAllModeScannerError(t *testing.T) {
 	cfg := &config.Config{Workers: 1}
 	st := &stats.SendStats{}
 
-	err := ProcessFileInSendAllMode(scanner, cfg, st)
+	err := ProcessFileInSendAllMode(context.Background(), scanner, cfg, st)
 	if err == nil {
 		t.Fatalf("expected error from scanner, got nil")
 	}
@@ -263,7 +284,7 @@ func TestSendErrorHandling(t *testing.T) {
 				Metrics: map[string]any{"resourceMetrics": []any{}},
 			}
 			st := &stats.SendStats{}
-			SendLastTelemetryData(lastData, failingCfg, st)
+			SendLastTelemetryData(context.Background(), lastData, failingCfg, st)
 			return st.TracesFailed == 1 && st.LogsFailed == 1 && st.MetricsFailed == 1, nil
 		},
 	)
@@ -287,7 +308,7 @@ func TestSendErrorHandling(t *testing.T) {
 			defer file.Close()
 
 			st := &stats.SendStats{}
-			if err := ProcessFileInSendAllMode(scanner, failingCfg, st); err != nil {
+			if err := ProcessFileInSendAllMode(context.Background(), scanner, failingCfg, st); err != nil {
 				return false, err
 			}
 
@@ -298,3 +319,439 @@ func TestSendErrorHandling(t *testing.T) {
 	tests := []c.CharacterizationTest[bool]{test1, test2}
 	c.VerifyCharacterizationTestsAndResults(t, tests, false)
 }
+
+func TestIngestTelemetryInvalidEncoding(t *testing.T) {
+	cfg := &config.Config{OTLPEncoding: "xml", MaxBufferCapacity: 1048576, Workers: 1}
+	err := IngestTelemetry(context.Background(), "/nonexistent/file.json", cfg)
+	var optErr *config.InvalidOptionError
+	if !errors.As(err, &optErr) {
+		t.Fatalf("expected InvalidOptionError, got %T: %v", err, err)
+	}
+}
+
+func TestIngestTelemetrySendAllModeGRPC(t *testing.T) {
+	mock := testutil.NewMockOTelGRPCCollector()
+	defer mock.Close()
+	tmpPath, err := createTempTestFile(`{"resourceSpans":[{"scopeSpans":[]}]}
+{"resourceLogs":[{"scopeLogs":[]}],"resourceMetrics":[{"scopeMetrics":[]}]}`, "test-grpc-*.json")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+	cfg := &config.Config{
+		OtelEndpoint:        mock.Endpoint(),
+		OtelLogsEndpoint:    mock.Endpoint(),
+		OtelMetricsEndpoint: mock.Endpoint(),
+		Protocol:            config.PROTOCOL_GRPC,
+		MaxBufferCapacity:   1048576,
+		SendAll:             true,
+		Workers:             2,
+	}
+	if err := IngestTelemetry(context.Background(), tmpPath, cfg); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	traces, logs, metrics, _ := mock.GetStats()
+	if traces != 1 || logs != 1 || metrics != 1 {
+		t.Errorf("Expected one export per type, got traces=%d logs=%d metrics=%d", traces, logs, metrics)
+	}
+}
+
+func TestIngestTelemetryMissingAuthSecret(t *testing.T) {
+	tmpPath, err := createTempTestFile(`{"resourceSpans":[]}`, "test-auth-*.json")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+	for _, sendAll := range []bool{false, true} {
+		cfg := &config.Config{
+			MaxBufferCapacity: 1048576,
+			SendAll:           sendAll,
+			Workers:           1,
+			AuthMode:          config.AUTH_BEARER,
+		}
+		err := IngestTelemetry(context.Background(), tmpPath, cfg)
+		var missingErr *config.MissingSecretError
+		if !errors.As(err, &missingErr) {
+			t.Errorf("sendAll=%v: expected MissingSecretError, got %T: %v", sendAll, err, err)
+		}
+	}
+}
+
+func TestIngestTelemetrySendAllModeCancelled(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	tmpPath, err := createTempTestFile(`{"resourceSpans":[{"scopeSpans":[]}]}
+{"resourceSpans":[{"scopeSpans":[]}]}`, "test-cancel-*.json")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+	cfg := &config.Config{
+		OtelEndpoint:      mock.TracesURL(),
+		MaxBufferCapacity: 1048576,
+		SendAll:           true,
+		Workers:           1,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = IngestTelemetry(ctx, tmpPath, cfg)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %T: %v", err, err)
+	}
+	if _, _, _, total := mock.GetStats(); total != 0 {
+		t.Errorf("Expected no requests after cancellation, got %d", total)
+	}
+}
+
+func TestIngestTelemetrySendAllModeDrainsOnShutdown(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		grace     time.Duration
+		lastAcked int
+	}{
+		{name: "drained within grace period", grace: 5 * time.Second, lastAcked: 1},
+		{name: "grace period expired", grace: 0, lastAcked: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Shut down while the first request is in flight
+				cancel()
+				select {
+				case <-time.After(50 * time.Millisecond):
+				case <-r.Context().Done():
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			tmpPath, err := createTempTestFile(`{"resourceSpans":[{"scopeSpans":[]}]}`, "test-shutdown-*.json")
+			if err != nil {
+				t.Fatalf("failed to create temp file: %v", err)
+			}
+			defer os.Remove(tmpPath)
+			cfg := &config.Config{
+				OtelEndpoint:        server.URL,
+				MaxBufferCapacity:   1048576,
+				SendAll:             true,
+				Workers:             1,
+				ShutdownGracePeriod: tc.grace,
+			}
+
+			err = IngestTelemetry(ctx, tmpPath, cfg)
+			var interrupted *InterruptedError
+			if !errors.As(err, &interrupted) {
+				t.Fatalf("expected InterruptedError, got %T: %v", err, err)
+			}
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected the error to wrap context.Canceled, got %v", err)
+			}
+			if interrupted.LastHandledLine != tc.lastAcked {
+				t.Errorf("expected last handled line %d, got %d", tc.lastAcked, interrupted.LastHandledLine)
+			}
+		})
+	}
+}
+
+func TestInterruptedError(t *testing.T) {
+	err := &InterruptedError{LastHandledLine: 42, FailedLines: 3, Err: context.Canceled}
+	if err.Error() != "ingestion interrupted, last fully handled line 42, 3 failed lines: context canceled" {
+		t.Errorf("unexpected error message: %s", err.Error())
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Error("expected InterruptedError to unwrap to context.Canceled")
+	}
+}
+
+func TestIngestTelemetrySendAllModeBatched(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	tmpPath, err := createTempTestFile(`{"resourceSpans":[{"scopeSpans":[{"spans":[{"name":"a"}]}]}]}
+{"resourceSpans":[{"scopeSpans":[{"spans":[{"name":"b"}]}]}],"resourceLogs":[{"scopeLogs":[]}]}
+
+{"resourceSpans":[{"scopeSpans":[{"spans":[{"name":"c"}]}]}]}`, "test-batch-*.json")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+	cfg := &config.Config{
+		OtelEndpoint:       mock.TracesURL(),
+		OtelLogsEndpoint:   mock.LogsURL(),
+		MaxBufferCapacity:  1048576,
+		SendAll:            true,
+		Workers:            2,
+		Batch:              true,
+		BatchMaxItems:      100,
+		BatchFlushInterval: time.Minute,
+	}
+	if err := IngestTelemetry(context.Background(), tmpPath, cfg); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	traces, logs, _, total := mock.GetStats()
+	if traces != 1 || logs != 1 || total != 2 {
+		t.Fatalf("Expected one batched request per type, got traces=%d logs=%d total=%d", traces, logs, total)
+	}
+	resources, _ := mock.ReceivedTraces[0]["resourceSpans"].([]any)
+	if len(resources) != 3 {
+		t.Errorf("Expected the trace batch to merge 3 resources, got %d", len(resources))
+	}
+}
+
+func TestIngestTelemetrySendAllModeFanOut(t *testing.T) {
+	fast := testutil.NewMockOTelCollector()
+	defer fast.Close()
+	release := make(chan struct{})
+	var slowRequests sync.WaitGroup
+	slowRequests.Add(50)
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+		slowRequests.Done()
+	}))
+	defer slow.Close()
+
+	// Far more lines than the queues of a single worker hold, so the slow destination
+	// would stall the fast one if they shared a queue
+	tmpPath, err := createTempTestFile(traceLines(50), "test-fan-out-*.json")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+	cfg := &config.Config{
+		OtelEndpoint:      fast.TracesURL() + "," + slow.URL + "/v1/traces",
+		MaxBufferCapacity: 1048576,
+		SendAll:           true,
+		Workers:           1,
+	}
+	done := make(chan error, 1)
+	go func() { done <- IngestTelemetry(context.Background(), tmpPath, cfg) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if traces, _, _, _ := fast.GetStats(); traces == 50 {
+			break
+		}
+		if time.Now().After(deadline) {
+			close(release)
+			t.Fatal("Expected the fast destination to receive every line while the slow one is stalled")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	slowRequests.Wait()
+}
+
+func TestIngestTelemetrySendAllModeBoundsStalledDestination(t *testing.T) {
+	release := make(chan struct{})
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer stalled.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	input := &endlessLines{ctx: ctx}
+	cfg := &config.Config{
+		OtelEndpoint:      stalled.URL + "/v1/traces",
+		MaxBufferCapacity: 1048576,
+		SendAll:           true,
+		Workers:           1,
+		QueueSize:         10,
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- ProcessFileInSendAllMode(ctx, NewTelemetryScanner(input, cfg.MaxBufferCapacity), cfg, &stats.SendStats{})
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	// The queue, the shared channel, the job in flight, the jobs held by the dispatcher
+	// and the reading loop, and one line of slack for the scanner reading ahead
+	const limit = 10 + 2 + 1 + 1 + 1 + 1
+	if read := input.lines.Load(); read > limit {
+		t.Errorf("Expected reading to wait for the stalled destination after %d lines, read %d", limit, read)
+	}
+	cancel()
+	close(release)
+	<-done
+}
+
+func TestBuildTelemetryJobsFanOut(t *testing.T) {
+	cfg := &config.Config{OtelEndpoint: "http://a/v1/traces, http://b/v1/traces", OtelLogsEndpoint: "http://a/v1/logs"}
+	data := map[string]any{resourceSpansField: []any{}, resourceLogsField: []any{}}
+	var endpoints []string
+	for _, job := range BuildTelemetryJobs(data, 1, cfg) {
+		endpoints = append(endpoints, job.Endpoint)
+	}
+	if fmt.Sprint(endpoints) != "[http://a/v1/traces http://b/v1/traces http://a/v1/logs]" {
+		t.Errorf("Expected one job per destination, got %v", endpoints)
+	}
+}
+
+func TestIngestTelemetryFileExporter(t *testing.T) {
+	tmpPath, err := createTempTestFile(`{"resourceSpans":[{"scopeSpans":[]}]}
+{"resourceLogs":[{"scopeLogs":[]}],"resourceMetrics":[{"scopeMetrics":[]}]}`, "test-file-exporter-*.json")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+	exportPath := t.TempDir() + "/export.jsonl"
+	cfg := &config.Config{
+		OtelEndpoint:      "http://a/v1/traces,http://b/v1/traces",
+		Exporter:          config.EXPORTER_FILE,
+		ExportFile:        exportPath,
+		MaxBufferCapacity: 1048576,
+		SendAll:           true,
+		Workers:           1,
+	}
+	if err := IngestTelemetry(context.Background(), tmpPath, cfg); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatalf("failed to read export file: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Errorf("Expected one exported line per signal and source line regardless of endpoints, got %d", lines)
+	}
+}
+
+func TestIngestTelemetrySendAllModeDeliversQueuedLinesOnScanError(t *testing.T) {
+	content := traceLines(200) + `{"resourceSpans":[{"scopeSpans":[],"padding":"` + strings.Repeat("x", 512) + `"}]}` + "\n"
+	tmpPath, err := createTempTestFile(content, "test-scan-error-*.json")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+	exportPath := t.TempDir() + "/export.jsonl"
+	cfg := &config.Config{
+		OtelEndpoint:      "http://a/v1/traces",
+		Exporter:          config.EXPORTER_FILE,
+		ExportFile:        exportPath,
+		MaxBufferCapacity: 256,
+		SendAll:           true,
+		Workers:           4,
+	}
+	if err := IngestTelemetry(context.Background(), tmpPath, cfg); !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatalf("expected bufio.ErrTooLong, got %v", err)
+	}
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatalf("failed to read export file: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 200 {
+		t.Errorf("Expected every line read before the error to be exported, got %d", lines)
+	}
+}
+
+func TestIngestTelemetryDryRun(t *testing.T) {
+	for _, sendAll := range []bool{false, true} {
+		mock := testutil.NewMockOTelCollector()
+		tmpPath, err := createTempTestFile(`{"resourceSpans":[{"scopeSpans":[]}]}
+{"resourceLogs":[{"scopeLogs":[]}]}`, "test-dry-run-*.json")
+		if err != nil {
+			t.Fatalf("failed to create temp file: %v", err)
+		}
+		deadLetterPath := t.TempDir() + "/dead.jsonl"
+		cfg := &config.Config{
+			OtelEndpoint:      mock.TracesURL(),
+			OtelLogsEndpoint:  mock.LogsURL(),
+			MaxBufferCapacity: 1048576,
+			SendAll:           sendAll,
+			Workers:           2,
+			DryRun:            true,
+			DeadLetterPath:    deadLetterPath,
+		}
+		if err := IngestTelemetry(context.Background(), tmpPath, cfg); err != nil {
+			t.Errorf("IngestTelemetry(sendAll=%v) returned error: %v", sendAll, err)
+		}
+		if _, _, _, total := mock.GetStats(); total != 0 {
+			t.Errorf("Expected a dry run (sendAll=%v) to make no requests, got %d", sendAll, total)
+		}
+		if _, err := os.Stat(deadLetterPath); !os.IsNotExist(err) {
+			t.Errorf("Expected a dry run (sendAll=%v) not to create the dead-letter file", sendAll)
+		}
+		mock.Close()
+		os.Remove(tmpPath)
+	}
+}
+
+func TestIngestTelemetryFromPipe(t *testing.T) {
+	stdin := os.Stdin
+	defer func() { os.Stdin = stdin }()
+	for _, sendAll := range []bool{false, true} {
+		mock := testutil.NewMockOTelCollector()
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("failed to create pipe: %v", err)
+		}
+		os.Stdin = r
+		go func() {
+			fmt.Fprintln(w, `{"resourceSpans":[{"scopeSpans":[]}]}`)
+			fmt.Fprintln(w, `{"resourceLogs":[{"scopeLogs":[]}]}`)
+			fmt.Fprintln(w, `{"resourceSpans":[{"scopeSpans":[]}]}`)
+			w.Close()
+		}()
+		cfg := &config.Config{
+			OtelEndpoint:      mock.TracesURL(),
+			OtelLogsEndpoint:  mock.LogsURL(),
+			MaxBufferCapacity: 1048576,
+			SendAll:           sendAll,
+			Workers:           2,
+		}
+		if err := IngestTelemetry(context.Background(), StdinPath, cfg); err != nil {
+			t.Errorf("IngestTelemetry(sendAll=%v) returned error: %v", sendAll, err)
+		}
+		traces, logs, _, _ := mock.GetStats()
+		expectedTraces := 1
+		if sendAll {
+			expectedTraces = 2
+		}
+		if traces != expectedTraces || logs != 1 {
+			t.Errorf("sendAll=%v: expected traces=%d logs=1, got traces=%d logs=%d", sendAll, expectedTraces, traces, logs)
+		}
+		r.Close()
+		mock.Close()
+	}
+}
+
+func TestIngestTelemetryFromStdin(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+	go func() {
+		fmt.Fprintln(w, `{"resourceMetrics":[{"scopeMetrics":[]}]}`)
+		w.Close()
+	}()
+
+	cfg := &config.Config{OtelMetricsEndpoint: mock.MetricsURL(), MaxBufferCapacity: 1048576}
+	if err := IngestTelemetry(context.Background(), StdinPath, cfg); err != nil {
+		t.Fatalf("IngestTelemetry returned error: %v", err)
+	}
+	if _, _, metrics, _ := mock.GetStats(); metrics != 1 {
+		t.Errorf("Expected 1 metrics request from stdin, got %d", metrics)
+	}
+	if _, err := r.Stat(); err != nil {
+		t.Errorf("Expected standard input to be left open, got %v", err)
+	}
+}
+
+func TestIngestReaderReadError(t *testing.T) {
+	cfg := &config.Config{MaxBufferCapacity: 1048576}
+	err := ingestReader(context.Background(), &errReader{err: errors.New("broken pipe")}, "stdin", cfg, &stats.SendStats{}, nil, nil)
+	var fre *FileReadError
+	if !errors.As(err, &fre) || fre.FilePath != "stdin" {
+		t.Errorf("Expected FileReadError naming stdin, got %T: %v", err, err)
+	}
+}
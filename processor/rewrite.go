@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/transform"
+)
+
+// newRewrite builds the transforms applied to every line of a run over inputs. Options
+// that need to see the whole run first, such as --rebase-timestamps, read the inputs
+// once up front. Options that keep state across lines, such as --regenerate-ids, share
+// it between every input of the run.
+func newRewrite(ctx context.Context, cfg *config.Config, inputs []string) (transform.Chain, error) {
+	var chain transform.Chain
+	if cfg.RebaseTimestamps {
+		rebaser, err := newRebaser(ctx, cfg, inputs)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, rebaser)
+	}
+	if cfg.RegenerateIDs {
+		slog.Info("Regenerating trace and span IDs")
+		chain = append(chain, transform.NewIDRemapper())
+	}
+	return chain, nil
+}
+
+// newRebaser finds the oldest and newest timestamps of inputs and returns a Rebaser
+// moving the configured anchor to the configured instant
+func newRebaser(ctx context.Context, cfg *config.Config, inputs []string) (*transform.Rebaser, error) {
+	var r transform.TimestampRange
+	for _, input := range inputs {
+		if input == StdinPath {
+			return nil, &SinglePassInputError{Option: "rebase-timestamps", Input: inputName(input)}
+		}
+		scanTimestamps(ctx, input, cfg.MaxBufferCapacity, &r)
+	}
+	target, err := cfg.RebaseTarget(time.Now())
+	if err != nil {
+		return nil, &config.InvalidOptionError{Option: "rebase-to", Value: cfg.RebaseTo}
+	}
+	if !r.Found {
+		slog.Warn("No timestamps found, nothing to rebase")
+		return &transform.Rebaser{}, nil
+	}
+
+	rebaser := transform.NewRebaser(r, cfg.RebaseAnchor, target)
+	slog.Info("Rebasing timestamps",
+		"oldest", time.Unix(0, r.Oldest).UTC(),
+		"newest", time.Unix(0, r.Newest).UTC(),
+		"anchor", time.Unix(0, r.Anchor(cfg.RebaseAnchor)).UTC(),
+		"target", target.UTC(),
+		"shift", time.Duration(rebaser.Shift))
+	return rebaser, nil
+}
+
+// scanTimestamps widens r with the timestamps of every line of the input at filePath.
+// Inputs that cannot be read are skipped here and reported when they are ingested.
+func scanTimestamps(ctx context.Context, filePath string, maxBufferCapacity int, r *transform.TimestampRange) {
+	input, err := OpenInput(filePath)
+	if err != nil {
+		return
+	}
+	defer input.Close()
+
+	scanner := NewTelemetryScanner(input, maxBufferCapacity)
+	for ctx.Err() == nil && scanner.Scan() {
+		var data s.TelemetryData
+		if err := json.Unmarshal(scanner.Bytes(), &data); err == nil {
+			r.Observe(data)
+		}
+	}
+}
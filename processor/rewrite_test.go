@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+)
+
+func TestIngestTelemetryFilesRebasesTimestamps(t *testing.T) {
+	for _, sendAll := range []bool{false, true} {
+		dir := t.TempDir()
+		first := writeInputFile(t, "a.jsonl", []byte(`{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"timeUnixNano":"1000000000"}]}]}]}`+"\n"))
+		second := writeInputFile(t, "b.jsonl", []byte(`{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"timeUnixNano":"3000000000"}]}]}]}`+"\n"))
+		exportPath := filepath.Join(dir, "export.jsonl")
+		cfg := &config.Config{
+			Exporter:          config.EXPORTER_FILE,
+			ExportFile:        exportPath,
+			MaxBufferCapacity: 1048576,
+			SendAll:           sendAll,
+			Workers:           1,
+			RebaseTimestamps:  true,
+			RebaseTo:          "2024-01-01T00:00:10Z",
+		}
+		if err := IngestTelemetryFiles(context.Background(), []string{first, second}, cfg); err != nil {
+			t.Fatalf("IngestTelemetryFiles(sendAll=%v) returned error: %v", sendAll, err)
+		}
+
+		data, err := os.ReadFile(exportPath)
+		if err != nil {
+			t.Fatalf("failed to read export file: %v", err)
+		}
+		// 2024-01-01T00:00:10Z is 1704067210s, the newest record of the run lands there
+		// and the other one keeps its 2s distance although it is in another file
+		for _, want := range []string{`"timeUnixNano":"1704067208000000000"`, `"timeUnixNano":"1704067210000000000"`} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("sendAll=%v: expected export to contain %s, got %s", sendAll, want, data)
+			}
+		}
+	}
+}
+
+func TestRebaseTimestampsRejectsStdin(t *testing.T) {
+	cfg := &config.Config{MaxBufferCapacity: 1048576, RebaseTimestamps: true}
+	var singlePass *SinglePassInputError
+	if err := IngestTelemetry(context.Background(), StdinPath, cfg); !errors.As(err, &singlePass) {
+		t.Errorf("Expected SinglePassInputError for standard input, got %T: %v", err, err)
+	}
+}
+
+func TestIngestTelemetryFilesRegeneratesIDsAcrossFiles(t *testing.T) {
+	const traceID = "5b8efff798038103d269b633813fc60c"
+	parent := writeInputFile(t, "parent.jsonl", []byte(`{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"`+traceID+`","spanId":"eee19b7ec3c1b174"}]}]}]}`+"\n"))
+	child := writeInputFile(t, "child.jsonl", []byte(`{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"`+traceID+`","spanId":"eee19b7ec3c1b173","parentSpanId":"eee19b7ec3c1b174"}]}]}]}`+"\n"))
+	exportPath := filepath.Join(t.TempDir(), "export.jsonl")
+	cfg := &config.Config{
+		Exporter:          config.EXPORTER_FILE,
+		ExportFile:        exportPath,
+		MaxBufferCapacity: 1048576,
+		SendAll:           true,
+		Workers:           1,
+		RegenerateIDs:     true,
+	}
+	if err := IngestTelemetryFiles(context.Background(), []string{parent, child}, cfg); err != nil {
+		t.Fatalf("IngestTelemetryFiles returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatalf("failed to read export file: %v", err)
+	}
+	var spans []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var payload map[string]any
+		if err := json.Unmarshal([]byte(line), &payload); err != nil {
+			t.Fatalf("failed to parse exported line %s: %v", line, err)
+		}
+		resource := payload["resourceSpans"].([]any)[0].(map[string]any)
+		scope := resource["scopeSpans"].([]any)[0].(map[string]any)
+		spans = append(spans, scope["spans"].([]any)[0].(map[string]any))
+	}
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 exported spans, got %d", len(spans))
+	}
+	if spans[0]["traceId"] == traceID || spans[0]["traceId"] != spans[1]["traceId"] {
+		t.Errorf("Expected both files to share one fresh trace ID, got %v and %v", spans[0]["traceId"], spans[1]["traceId"])
+	}
+	if spans[1]["parentSpanId"] != spans[0]["spanId"] {
+		t.Errorf("Expected the child in the second file to point at the new parent ID %v, got %v", spans[0]["spanId"], spans[1]["parentSpanId"])
+	}
+}
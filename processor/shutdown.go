@@ -0,0 +1,31 @@
+package processor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// withGracePeriod returns a context for sends that outlives ctx by the grace period.
+// Once ctx is cancelled, queued and in-flight jobs keep running until the grace
+// period expires, after which the returned context is cancelled as well.
+func withGracePeriod(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	drainCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-drainCtx.Done():
+			return
+		}
+		slog.Warn("Shutdown requested, draining in-flight telemetry", "grace_period", grace)
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			slog.Warn("Grace period expired, cancelling in-flight telemetry")
+			cancel()
+		case <-drainCtx.Done():
+		}
+	}()
+	return drainCtx, cancel
+}
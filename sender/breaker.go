@@ -0,0 +1,226 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BreakerState is the state of a CircuitBreaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets every request through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails every request fast until the cool-down has passed
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe request through to test the endpoint
+	BreakerHalfOpen
+
+	// noTransition is returned when a call left the breaker in the same state
+	noTransition BreakerState = -1
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker tracks the health of a single endpoint. It opens after Threshold
+// consecutive failures, stays open for Cooldown and then lets one probe through:
+// a successful probe closes it again, a failed probe reopens it.
+type CircuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Threshold: threshold, Cooldown: cooldown}
+}
+
+// State returns the current state of the breaker
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// Allow reports whether a request may be sent. Once the cool-down of an open breaker
+// has passed, the first caller moves it to half-open and becomes the probe. The
+// returned transition is the state the breaker moved to, if any.
+func (cb *CircuitBreaker) Allow() (bool, BreakerState) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case BreakerOpen:
+		if time.Since(cb.openedAt) < cb.Cooldown {
+			return false, noTransition
+		}
+		cb.state = BreakerHalfOpen
+		cb.probing = true
+		return true, BreakerHalfOpen
+	case BreakerHalfOpen:
+		if cb.probing {
+			return false, noTransition
+		}
+		cb.probing = true
+		return true, noTransition
+	default:
+		return true, noTransition
+	}
+}
+
+// Success records a request that reached a healthy endpoint and returns the resulting transition
+func (cb *CircuitBreaker) Success() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures = 0
+	cb.probing = false
+	if cb.state == BreakerClosed {
+		return noTransition
+	}
+	cb.state = BreakerClosed
+	return BreakerClosed
+}
+
+// Failure records a request that failed because the endpoint is unhealthy and returns the resulting transition
+func (cb *CircuitBreaker) Failure() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures++
+	cb.probing = false
+	if cb.state == BreakerOpen || (cb.state == BreakerClosed && cb.failures < cb.Threshold) {
+		return noTransition
+	}
+	cb.state = BreakerOpen
+	cb.openedAt = time.Now()
+	return BreakerOpen
+}
+
+// Release gives up the probe slot of a half-open breaker when the probe ended
+// without telling whether the endpoint is healthy
+func (cb *CircuitBreaker) Release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probing = false
+}
+
+// BreakerTransport guards every endpoint of the wrapped Transport with its own
+// CircuitBreaker. While the breaker of an endpoint is open, its jobs fail fast
+// with a CircuitOpenError instead of being sent.
+type BreakerTransport struct {
+	Transport
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+// NewBreakerTransport wraps next with circuit breakers configured from cfg
+func NewBreakerTransport(next Transport, cfg *config.Config) *BreakerTransport {
+	return &BreakerTransport{
+		Transport: next,
+		Threshold: cfg.BreakerThreshold,
+		Cooldown:  cfg.BreakerCooldown,
+		breakers:  make(map[string]*CircuitBreaker),
+	}
+}
+
+// SendJob sends the job through the wrapped Transport unless the breaker of its endpoint is open
+func (bt *BreakerTransport) SendJob(ctx context.Context, job structs.TelemetryJob, stats *stats.SendStats) error {
+	breaker := bt.breaker(job.Endpoint)
+	allowed, transition := breaker.Allow()
+	bt.record(job, transition, stats)
+	if !allowed {
+		stats.RecordFailure(job.TelemetryType)
+		stats.RecordShortCircuit(job.TelemetryType)
+		return &CircuitOpenError{Endpoint: job.Endpoint}
+	}
+
+	err := bt.Transport.SendJob(ctx, job, stats)
+	switch {
+	case err == nil:
+		bt.record(job, breaker.Success(), stats)
+	case ctx.Err() == nil && endpointUnhealthy(err):
+		bt.record(job, breaker.Failure(), stats)
+	default:
+		breaker.Release()
+	}
+	return err
+}
+
+// breaker returns the breaker guarding endpoint, creating it on first use
+func (bt *BreakerTransport) breaker(endpoint string) *CircuitBreaker {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+	breaker, ok := bt.breakers[endpoint]
+	if !ok {
+		breaker = NewCircuitBreaker(bt.Threshold, bt.Cooldown)
+		bt.breakers[endpoint] = breaker
+	}
+	return breaker
+}
+
+// record logs and counts a state change of the breaker guarding the job endpoint
+func (bt *BreakerTransport) record(job structs.TelemetryJob, transition BreakerState, stats *stats.SendStats) {
+	switch transition {
+	case BreakerOpen:
+		stats.RecordBreakerOpen()
+		slog.Warn("Circuit breaker opened, failing requests fast", "endpoint", job.Endpoint, "type", job.TelemetryType, "line", job.LineNum, "cooldown", bt.Cooldown)
+	case BreakerHalfOpen:
+		stats.RecordBreakerHalfOpen()
+		slog.Info("Circuit breaker half-open, probing endpoint", "endpoint", job.Endpoint, "type", job.TelemetryType, "line", job.LineNum)
+	case BreakerClosed:
+		stats.RecordBreakerClose()
+		slog.Info("Circuit breaker closed, endpoint recovered", "endpoint", job.Endpoint, "type", job.TelemetryType, "line", job.LineNum)
+	}
+}
+
+// endpointUnhealthy reports whether err shows that the endpoint is down or overloaded,
+// as opposed to a payload the collector refused
+func endpointUnhealthy(err error) bool {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || isRetryableStatus(statusErr.StatusCode)
+	}
+	var requestErr *HTTPRequestError
+	if errors.As(err, &requestErr) {
+		return true
+	}
+	var grpcErr *GRPCRequestError
+	if errors.As(err, &grpcErr) {
+		st, ok := status.FromError(grpcErr.Err)
+		if !ok {
+			return true
+		}
+		switch st.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown, codes.Aborted:
+			return true
+		}
+	}
+	return false
+}
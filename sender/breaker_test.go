@@ -0,0 +1,164 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCircuitBreakerStates(t *testing.T) {
+	cb := NewCircuitBreaker(2, 20*time.Millisecond)
+	if allowed, _ := cb.Allow(); !allowed {
+		t.Fatal("Expected a closed breaker to allow requests")
+	}
+	if transition := cb.Failure(); transition != noTransition {
+		t.Errorf("Expected the first failure to keep the breaker closed, got %v", transition)
+	}
+	if transition := cb.Failure(); transition != BreakerOpen {
+		t.Errorf("Expected the second failure to open the breaker, got %v", transition)
+	}
+	if allowed, _ := cb.Allow(); allowed {
+		t.Error("Expected an open breaker to fail requests fast")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if allowed, transition := cb.Allow(); !allowed || transition != BreakerHalfOpen {
+		t.Fatalf("Expected the first request after the cool-down to probe, got allowed=%v transition=%v", allowed, transition)
+	}
+	if allowed, _ := cb.Allow(); allowed {
+		t.Error("Expected a half-open breaker to allow a single probe")
+	}
+	if transition := cb.Failure(); transition != BreakerOpen {
+		t.Errorf("Expected a failed probe to reopen the breaker, got %v", transition)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	cb.Allow()
+	if transition := cb.Success(); transition != BreakerClosed || cb.State() != BreakerClosed {
+		t.Errorf("Expected a successful probe to close the breaker, got %v", transition)
+	}
+	if transition := cb.Failure(); transition != noTransition {
+		t.Errorf("Expected the failure count to reset after closing, got %v", transition)
+	}
+}
+
+func TestCircuitBreakerRelease(t *testing.T) {
+	cb := NewCircuitBreaker(1, 0)
+	cb.Failure()
+	cb.Allow()
+	cb.Release()
+	if allowed, _ := cb.Allow(); !allowed || cb.State() != BreakerHalfOpen {
+		t.Errorf("Expected a released probe slot to let the next request probe, got allowed=%v state=%v", allowed, cb.State())
+	}
+}
+
+func TestBreakerStateString(t *testing.T) {
+	newTest := func(state BreakerState, expected string) c.CharacterizationTest[string] {
+		return c.NewCharacterizationTest(expected, nil, func() (string, error) {
+			return state.String(), nil
+		})
+	}
+	tests := []c.CharacterizationTest[string]{
+		newTest(BreakerClosed, "closed"),
+		newTest(BreakerOpen, "open"),
+		newTest(BreakerHalfOpen, "half-open"),
+		newTest(BreakerState(42), "unknown"),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestBreakerTransport(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	mock.ShouldFail = true
+	cfg := config.NewConfig()
+	cfg.MaxRetries = 0
+	cfg.BreakerThreshold = 2
+	cfg.BreakerCooldown = 50 * time.Millisecond
+	bt := NewBreakerTransport(newTestHTTPSender(t, cfg), cfg)
+	defer bt.Close()
+
+	st := &stats.SendStats{}
+	logsJob := structs.TelemetryJob{Endpoint: mock.LogsURL(), Payload: map[string]any{"resourceLogs": []any{}}, TelemetryType: structs.TelemetryLogs}
+	for range 5 {
+		bt.SendJob(context.Background(), logsJob, st)
+	}
+	if _, _, _, total := mock.GetStats(); total != 2 {
+		t.Errorf("Expected the breaker to stop sending after 2 failures, collector got %d requests", total)
+	}
+	err := bt.SendJob(context.Background(), logsJob, st)
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || openErr.Endpoint != mock.LogsURL() {
+		t.Errorf("Expected CircuitOpenError for %s, got %T: %v", mock.LogsURL(), err, err)
+	}
+	if st.LogsFailed != 6 || st.LogsShortCircuited != 4 || st.BreakerOpened != 1 {
+		t.Errorf("Expected 6 failures with 4 short-circuited and 1 opening, got failed=%d short_circuited=%d opened=%d", st.LogsFailed, st.LogsShortCircuited, st.BreakerOpened)
+	}
+
+	mock.ShouldFail = false
+	tracesJob := structs.TelemetryJob{Endpoint: mock.TracesURL(), Payload: map[string]any{"resourceSpans": []any{}}, TelemetryType: structs.TelemetryTraces}
+	if err := bt.SendJob(context.Background(), tracesJob, st); err != nil {
+		t.Errorf("Expected the traces endpoint to be unaffected by the logs breaker, got %v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if err := bt.SendJob(context.Background(), logsJob, st); err != nil {
+		t.Fatalf("Expected the probe to succeed once the collector recovered, got %v", err)
+	}
+	if st.BreakerHalfOpened != 1 || st.BreakerClosed != 1 {
+		t.Errorf("Expected the breaker to go half-open and close, got half_opened=%d closed=%d", st.BreakerHalfOpened, st.BreakerClosed)
+	}
+	if err := bt.SendJob(context.Background(), logsJob, st); err != nil {
+		t.Errorf("Expected a closed breaker to send again, got %v", err)
+	}
+}
+
+func TestBreakerTransportIgnoresRejectedPayloads(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	cfg := config.NewConfig()
+	cfg.BreakerThreshold = 1
+	bt := NewBreakerTransport(newTestHTTPSender(t, cfg), cfg)
+	defer bt.Close()
+
+	st := &stats.SendStats{}
+	job := structs.TelemetryJob{Endpoint: mock.TracesURL(), Payload: map[string]any{"resourceSpans": []any{}}, TelemetryType: structs.TelemetryTraces}
+	for range 3 {
+		mock.QueueResponses(testutil.MockResponse{StatusCode: http.StatusBadRequest})
+		bt.SendJob(context.Background(), job, st)
+	}
+	if _, _, _, total := mock.GetStats(); total != 3 || st.BreakerOpened != 0 {
+		t.Errorf("Expected 400 responses not to open the breaker, got requests=%d opened=%d", total, st.BreakerOpened)
+	}
+}
+
+func TestEndpointUnhealthy(t *testing.T) {
+	newTest := func(err error, expected bool) c.CharacterizationTest[bool] {
+		return c.NewCharacterizationTest(expected, nil, func() (bool, error) {
+			return endpointUnhealthy(err), nil
+		})
+	}
+	tests := []c.CharacterizationTest[bool]{
+		newTest(&HTTPStatusError{StatusCode: http.StatusServiceUnavailable}, true),
+		newTest(&HTTPStatusError{StatusCode: http.StatusInternalServerError}, true),
+		newTest(&HTTPStatusError{StatusCode: http.StatusTooManyRequests}, true),
+		newTest(&HTTPStatusError{StatusCode: http.StatusBadRequest}, false),
+		newTest(&HTTPRequestError{Err: errors.New("connection refused")}, true),
+		newTest(&GRPCRequestError{Err: status.Error(codes.Unavailable, "down")}, true),
+		newTest(&GRPCRequestError{Err: status.Error(codes.InvalidArgument, "bad")}, false),
+		newTest(&GRPCRequestError{Err: errors.New("dial failed")}, true),
+		newTest(errors.Join(&JobError{Err: &HTTPStatusError{StatusCode: http.StatusBadGateway}}), true),
+		newTest(&JSONMarshalError{Err: errors.New("bad payload")}, false),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
@@ -0,0 +1,46 @@
+package sender
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+)
+
+// NewHTTPClient creates the HTTP client shared by every worker of a run.
+// ConnectTimeout bounds dialing and the TLS handshake, ResponseTimeout bounds the wait
+// for response headers and RequestTimeout bounds each request as a whole. Every
+// destination has its own pool of workers, and the traces, logs and metrics paths of a
+// collector share its host, so the idle pool keeps one connection per worker of every
+// destination, all of which may go to a single host, and keep-alives are reused.
+func NewHTTPClient(cfg *config.Config) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.ConnectTimeout, KeepAlive: 30 * time.Second}
+	poolSize := max(cfg.Workers, 1) * max(destinationCount(cfg), 1)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.TLSClientConfig = tlsConfig
+	transport.TLSHandshakeTimeout = cfg.ConnectTimeout
+	transport.ResponseHeaderTimeout = cfg.ResponseTimeout
+	transport.MaxIdleConnsPerHost = poolSize
+	transport.MaxIdleConns = poolSize
+
+	return &http.Client{Transport: transport, Timeout: cfg.RequestTimeout}, nil
+}
+
+// destinationCount returns the number of distinct endpoints configured across every signal
+func destinationCount(cfg *config.Config) int {
+	destinations := make(map[string]bool)
+	for _, endpoints := range []string{cfg.OtelEndpoint, cfg.OtelLogsEndpoint, cfg.OtelMetricsEndpoint} {
+		for _, endpoint := range config.SplitEndpoints(endpoints) {
+			destinations[endpoint] = true
+		}
+	}
+	return len(destinations)
+}
@@ -0,0 +1,173 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Workers = 4
+	cfg.ConnectTimeout = 2 * time.Second
+	cfg.ResponseTimeout = 3 * time.Second
+	cfg.RequestTimeout = 5 * time.Second
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		t.Fatalf("NewHTTPClient returned error: %v", err)
+	}
+	if client.Timeout != 5*time.Second {
+		t.Errorf("Expected overall timeout 5s, got %v", client.Timeout)
+	}
+	transport := client.Transport.(*http.Transport)
+	if transport.TLSHandshakeTimeout != 2*time.Second || transport.ResponseHeaderTimeout != 3*time.Second {
+		t.Errorf("Unexpected transport timeouts: handshake=%v response=%v", transport.TLSHandshakeTimeout, transport.ResponseHeaderTimeout)
+	}
+	// The three default endpoints share a host, so it may get every connection
+	if transport.MaxIdleConnsPerHost != 12 || transport.MaxIdleConns != 12 {
+		t.Errorf("Expected idle pool of 12 per host and overall, got %d and %d", transport.MaxIdleConnsPerHost, transport.MaxIdleConns)
+	}
+
+	cfg.OtelEndpoint += ",http://backup:4318/v1/traces"
+	client, err = NewHTTPClient(cfg)
+	if err != nil {
+		t.Fatalf("NewHTTPClient returned error: %v", err)
+	}
+	if transport := client.Transport.(*http.Transport); transport.MaxIdleConns != 16 {
+		t.Errorf("Expected an idle pool of 16 for 4 destinations of 4 workers, got %d", transport.MaxIdleConns)
+	}
+}
+
+func TestNewHTTPClientTLSError(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.TLSCAFile = "/nonexistent/ca.pem"
+	_, err := NewHTTPClient(cfg)
+	var tlsErr *TLSConfigError
+	if !errors.As(err, &tlsErr) {
+		t.Errorf("Expected TLSConfigError, got %T: %v", err, err)
+	}
+}
+
+type countingRoundTripper struct {
+	calls atomic.Int32
+}
+
+func (c *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.calls.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewHTTPSenderWithClient(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	rt := &countingRoundTripper{}
+	hs, err := NewHTTPSenderWithClient(config.NewConfig(), &http.Client{Transport: rt})
+	if err != nil {
+		t.Fatalf("NewHTTPSenderWithClient returned error: %v", err)
+	}
+	st := &stats.SendStats{}
+	if err := hs.Send(context.Background(), mock.TracesURL(), map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if rt.calls.Load() != 1 || st.TracesSuccess != 1 {
+		t.Errorf("Expected one request through the injected client, got calls=%d success=%d", rt.calls.Load(), st.TracesSuccess)
+	}
+}
+
+func TestHTTPSenderResponseTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := config.NewConfig()
+	cfg.MaxRetries = 0
+	cfg.ResponseTimeout = 20 * time.Millisecond
+	hs := newTestHTTPSender(t, cfg)
+	st := &stats.SendStats{}
+
+	start := time.Now()
+	err := hs.Send(context.Background(), server.URL, map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st)
+	var reqErr *HTTPRequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("Expected HTTPRequestError, got %T: %v", err, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the response timeout to abort the request, took %v", elapsed)
+	}
+	if st.TracesFailed != 1 {
+		t.Errorf("Expected one failed trace, got %d", st.TracesFailed)
+	}
+}
+
+func TestHTTPSenderCancelledContext(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	hs := newTestHTTPSender(t, config.NewConfig())
+	st := &stats.SendStats{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := hs.Send(ctx, mock.TracesURL(), map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %T: %v", err, err)
+	}
+	if _, _, _, total := mock.GetStats(); total != 0 {
+		t.Errorf("Expected no request to reach the collector, got %d", total)
+	}
+	if st.TracesFailed != 1 || st.TracesRetries != 0 {
+		t.Errorf("Expected one failure without retries, got failed=%d retries=%d", st.TracesFailed, st.TracesRetries)
+	}
+}
+
+func TestRetryCancelledDuringBackoff(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	mock.QueueResponses(testutil.MockResponse{StatusCode: http.StatusServiceUnavailable, RetryAfter: "60"})
+	hs := &HTTPSender{Retry: patientRetry}
+	st := &stats.SendStats{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	hs.Send(ctx, mock.TracesURL(), map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancellation to interrupt the Retry-After wait, took %v", elapsed)
+	}
+	if _, _, _, total := mock.GetStats(); total != 1 {
+		t.Errorf("Expected a single attempt before cancellation, got %d", total)
+	}
+	if st.TracesFailed != 1 || st.TracesGaveUp != 0 {
+		t.Errorf("Expected one failure without a give-up, got failed=%d gave_up=%d", st.TracesFailed, st.TracesGaveUp)
+	}
+}
+
+func TestGRPCSenderCancelledContext(t *testing.T) {
+	mock := testutil.NewMockOTelGRPCCollector()
+	defer mock.Close()
+	gs := newTestGRPCSender(t, config.NewConfig())
+	defer gs.Close()
+	st := &stats.SendStats{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := gs.Send(ctx, mock.Endpoint(), map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st)
+	var grpcErr *GRPCRequestError
+	if !errors.As(err, &grpcErr) {
+		t.Fatalf("Expected GRPCRequestError, got %T: %v", err, err)
+	}
+	if st.TracesFailed != 1 || st.TracesRetries != 0 {
+		t.Errorf("Expected one failure without retries, got failed=%d retries=%d", st.TracesFailed, st.TracesRetries)
+	}
+}
@@ -0,0 +1,40 @@
+package sender
+
+import (
+	"bytes"
+	"compress/gzip"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/laiambryant/telemetry-ingestor/config"
+)
+
+// zstdEncoder is shared by all workers, EncodeAll is safe for concurrent use
+var zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+	return zstd.NewWriter(nil)
+})
+
+// compressBody compresses the body and returns it with the matching Content-Encoding.
+// An empty Content-Encoding means the body was left untouched.
+func compressBody(body []byte, compression string) ([]byte, string, error) {
+	switch compression {
+	case config.COMPRESSION_GZIP:
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(body); err != nil {
+			return nil, "", &CompressionError{Compression: compression, Err: err}
+		}
+		if err := gz.Close(); err != nil {
+			return nil, "", &CompressionError{Compression: compression, Err: err}
+		}
+		return buf.Bytes(), config.COMPRESSION_GZIP, nil
+	case config.COMPRESSION_ZSTD:
+		encoder, err := zstdEncoder()
+		if err != nil {
+			return nil, "", &CompressionError{Compression: compression, Err: err}
+		}
+		return encoder.EncodeAll(body, make([]byte, 0, len(body)/2)), config.COMPRESSION_ZSTD, nil
+	default:
+		return body, "", nil
+	}
+}
@@ -0,0 +1,99 @@
+package sender
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+func TestCompressBody(t *testing.T) {
+	body := bytes.Repeat([]byte(`{"resourceSpans":[]}`), 100)
+
+	gzipped, encoding, err := compressBody(body, config.COMPRESSION_GZIP)
+	if err != nil || encoding != "gzip" {
+		t.Fatalf("Expected gzip compression, got encoding=%q err=%v", encoding, err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(gzipped))
+	if err != nil {
+		t.Fatalf("Failed to open gzip body: %v", err)
+	}
+	if decoded, _ := io.ReadAll(gz); !bytes.Equal(decoded, body) {
+		t.Error("Expected gzip body to round trip")
+	}
+
+	zstded, encoding, err := compressBody(body, config.COMPRESSION_ZSTD)
+	if err != nil || encoding != "zstd" {
+		t.Fatalf("Expected zstd compression, got encoding=%q err=%v", encoding, err)
+	}
+	decoder, _ := zstd.NewReader(nil)
+	defer decoder.Close()
+	if decoded, err := decoder.DecodeAll(zstded, nil); err != nil || !bytes.Equal(decoded, body) {
+		t.Errorf("Expected zstd body to round trip, err=%v", err)
+	}
+
+	for _, compression := range []string{"", config.COMPRESSION_NONE} {
+		plain, encoding, err := compressBody(body, compression)
+		if err != nil || encoding != "" || !bytes.Equal(plain, body) {
+			t.Errorf("Expected %q to leave the body untouched, got encoding=%q err=%v", compression, encoding, err)
+		}
+	}
+}
+
+func TestHTTPSenderCompression(t *testing.T) {
+	for _, encoding := range []string{config.ENCODING_JSON, config.ENCODING_PROTOBUF} {
+		for _, compression := range []string{config.COMPRESSION_GZIP, config.COMPRESSION_ZSTD} {
+			mock := testutil.NewMockOTelCollector()
+			st := &stats.SendStats{}
+			hs := newTestHTTPSender(t, &config.Config{OTLPEncoding: encoding, Compression: compression})
+			payload := map[string]any{"resourceLogs": []any{map[string]any{"scopeLogs": []any{map[string]any{"logRecords": []any{map[string]any{"severityText": "INFO"}}}}}}}
+			if err := hs.Send(context.Background(), mock.LogsURL(), payload, structs.TelemetryLogs, st); err != nil {
+				t.Fatalf("%s/%s: Send returned error: %v", encoding, compression, err)
+			}
+			if st.LogsSuccess != 1 {
+				t.Errorf("%s/%s: Expected collector to accept the body, got %d successes", encoding, compression, st.LogsSuccess)
+			}
+			if got := mock.ReceivedHeaders[0].Get("Content-Encoding"); got != compression {
+				t.Errorf("%s/%s: Expected Content-Encoding %s, got %q", encoding, compression, compression, got)
+			}
+			record := mock.ReceivedLogs[0]["resourceLogs"].([]any)[0].(map[string]any)["scopeLogs"].([]any)[0].(map[string]any)["logRecords"].([]any)[0].(map[string]any)
+			if record["severityText"] != "INFO" {
+				t.Errorf("%s/%s: Expected decoded log record, got %v", encoding, compression, record)
+			}
+			mock.Close()
+		}
+	}
+}
+
+func TestHTTPSenderNoCompressionHeader(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	st := &stats.SendStats{}
+	if err := newTestHTTPSender(t, config.NewConfig()).Send(context.Background(), mock.TracesURL(), map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if got := mock.ReceivedHeaders[0].Get("Content-Encoding"); got != "" {
+		t.Errorf("Expected no Content-Encoding, got %q", got)
+	}
+}
+
+func TestGRPCSenderGzipCompression(t *testing.T) {
+	mock := testutil.NewMockOTelGRPCCollector()
+	defer mock.Close()
+	gs := newTestGRPCSender(t, &config.Config{Compression: config.COMPRESSION_GZIP})
+	defer gs.Close()
+	st := &stats.SendStats{}
+	if err := gs.Send(context.Background(), mock.Endpoint(), map[string]any{"resourceMetrics": []any{}}, structs.TelemetryMetrics, st); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if _, _, metrics, _ := mock.GetStats(); metrics != 1 || st.MetricsSuccess != 1 {
+		t.Errorf("Expected gzip compressed export to be accepted, got metrics=%d success=%d", metrics, st.MetricsSuccess)
+	}
+}
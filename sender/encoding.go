@@ -0,0 +1,36 @@
+package sender
+
+import (
+	"encoding/json"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/otlp"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	contentTypeJSON     = "application/json"
+	contentTypeProtobuf = "application/x-protobuf"
+)
+
+// encodePayload serializes the payload with the requested OTLP encoding and returns the body and its Content-Type
+func encodePayload(payload any, telemetryType structs.TelemetryType, encoding string) ([]byte, string, error) {
+	if encoding == config.ENCODING_PROTOBUF {
+		msg, err := otlp.ToProto(payload, telemetryType)
+		if err != nil {
+			return nil, "", &ProtobufMarshalError{Err: err}
+		}
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			return nil, "", &ProtobufMarshalError{Err: err}
+		}
+		return data, contentTypeProtobuf, nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", &JSONMarshalError{Err: err}
+	}
+	return data, contentTypeJSON, nil
+}
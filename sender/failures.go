@@ -0,0 +1,49 @@
+package sender
+
+import (
+	"errors"
+
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"google.golang.org/grpc/status"
+)
+
+// FailedJob is a job, or a part of a split job, that could not be delivered
+type FailedJob struct {
+	Job structs.TelemetryJob
+	Err error
+}
+
+// FailedJobs returns what was not delivered when sending job failed with err. When the job
+// was split, only the parts that failed are returned, otherwise the whole job is.
+func FailedJobs(job structs.TelemetryJob, err error) []FailedJob {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []FailedJob{{Job: job, Err: err}}
+	}
+	var failed []FailedJob
+	for _, e := range joined.Unwrap() {
+		var jobErr *JobError
+		if errors.As(e, &jobErr) {
+			failed = append(failed, FailedJob{Job: jobErr.Job, Err: jobErr.Err})
+			continue
+		}
+		failed = append(failed, FailedJob{Job: job, Err: e})
+	}
+	return failed
+}
+
+// StatusCode returns the HTTP status code or gRPC status code a collector answered a failed
+// request with, or 0 when the request never got an answer
+func StatusCode(err error) int {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	var grpcErr *GRPCRequestError
+	if errors.As(err, &grpcErr) {
+		if st, ok := status.FromError(grpcErr.Err); ok {
+			return int(st.Code())
+		}
+	}
+	return 0
+}
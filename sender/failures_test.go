@@ -0,0 +1,53 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFailedJobs(t *testing.T) {
+	job := structs.TelemetryJob{LineNum: 1, Payload: "whole"}
+	first := structs.TelemetryJob{LineNum: 1, Payload: "first"}
+	second := structs.TelemetryJob{LineNum: 1, Payload: "second"}
+	plain := errors.New("plain")
+
+	newTest := func(err error, expected string) c.CharacterizationTest[string] {
+		return c.NewCharacterizationTest(expected, nil, func() (string, error) {
+			var out []string
+			for _, failed := range FailedJobs(job, err) {
+				out = append(out, fmt.Sprintf("%v:%v", failed.Job.Payload, failed.Err))
+			}
+			return fmt.Sprint(out), nil
+		})
+	}
+	tests := []c.CharacterizationTest[string]{
+		newTest(plain, "[whole:plain]"),
+		newTest(errors.Join(&JobError{Job: first, Err: plain}), "[first:plain]"),
+		newTest(errors.Join(&JobError{Job: first, Err: plain}, &JobError{Job: second, Err: plain}), "[first:plain second:plain]"),
+		newTest(errors.Join(plain), "[whole:plain]"),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestStatusCode(t *testing.T) {
+	newTest := func(err error, expected int) c.CharacterizationTest[int] {
+		return c.NewCharacterizationTest(expected, nil, func() (int, error) {
+			return StatusCode(err), nil
+		})
+	}
+	tests := []c.CharacterizationTest[int]{
+		newTest(&HTTPStatusError{StatusCode: 503}, 503),
+		newTest(&JobError{Err: &HTTPStatusError{StatusCode: 400}}, 400),
+		newTest(&GRPCRequestError{Err: status.Error(codes.Unavailable, "down")}, int(codes.Unavailable)),
+		newTest(&GRPCRequestError{Err: errors.New("dial failed")}, 0),
+		newTest(&HTTPRequestError{Err: errors.New("connection refused")}, 0),
+		newTest(nil, 0),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
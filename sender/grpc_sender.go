@@ -0,0 +1,200 @@
+package sender
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/otlp"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	collogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// GRPCSender sends telemetry data to an OpenTelemetry collector over OTLP/gRPC.
+// Connections are opened lazily and shared by every worker targeting the same endpoint.
+type GRPCSender struct {
+	Compression string
+	Retry       RetryPolicy
+	Headers     map[structs.TelemetryType]map[string]string
+	// ConnectTimeout bounds establishing a connection and RequestTimeout bounds each Export call
+	ConnectTimeout time.Duration
+	RequestTimeout time.Duration
+	// MaxRequestBytes splits payloads whose encoded size exceeds it, 0 disables splitting
+	MaxRequestBytes int
+	tlsConfig       *tls.Config
+	forceTLS        bool
+	mu              sync.Mutex
+	conns           map[string]*grpc.ClientConn
+}
+
+// NewGRPCSender creates a GRPCSender configured from cfg
+func NewGRPCSender(cfg *config.Config) (*GRPCSender, error) {
+	headers, err := resolveHeaders(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &GRPCSender{
+		Compression:     cfg.Compression,
+		Retry:           NewRetryPolicy(cfg),
+		Headers:         headers,
+		ConnectTimeout:  cfg.ConnectTimeout,
+		RequestTimeout:  cfg.RequestTimeout,
+		MaxRequestBytes: cfg.MaxRequestBytes,
+		tlsConfig:       tlsConfig,
+		forceTLS:        cfg.TLSConfigured(),
+		conns:           make(map[string]*grpc.ClientConn),
+	}, nil
+}
+
+// Send converts the payload to its Export*ServiceRequest and calls the matching Export RPC, retrying retryable failures
+func (gs *GRPCSender) Send(ctx context.Context, endpoint string, payload any, telemetryType structs.TelemetryType, stats *stats.SendStats) error {
+	return gs.SendJob(ctx, structs.TelemetryJob{Endpoint: endpoint, Payload: payload, TelemetryType: telemetryType}, stats)
+}
+
+// SendJob converts the job payload to its Export*ServiceRequest and calls the matching Export RPC, retrying retryable failures.
+// Cancelling ctx aborts the call in flight and any pending retry.
+func (gs *GRPCSender) SendJob(ctx context.Context, job structs.TelemetryJob, stats *stats.SendStats) error {
+	msg, err := otlp.ToProto(job.Payload, job.TelemetryType)
+	if err != nil {
+		return &ProtobufMarshalError{Err: err}
+	}
+	if gs.MaxRequestBytes > 0 && proto.Size(msg) > gs.MaxRequestBytes {
+		return sendSplit(ctx, job, gs.MaxRequestBytes, protoSize(job.TelemetryType), stats, gs.SendJob)
+	}
+
+	conn, err := gs.conn(job.Endpoint)
+	if err != nil {
+		stats.RecordFailure(job.TelemetryType)
+		return &GRPCRequestError{Endpoint: job.Endpoint, Err: err}
+	}
+
+	stats.RecordDispatched(job.TelemetryType, otlp.CountItems(job.Payload, job.TelemetryType), int64(proto.Size(msg)))
+	ctx = metadata.NewOutgoingContext(ctx, metadata.New(gs.Headers[job.TelemetryType]))
+	var accepted proto.Message
+	err = gs.Retry.run(ctx, job.TelemetryType, stats, func() (bool, time.Duration, error) {
+		resp, err := gs.export(ctx, conn, msg)
+		if err == nil {
+			accepted = resp
+			return false, 0, nil
+		}
+		retryable, retryAfter := grpcRetryInfo(err)
+		return retryable, retryAfter, err
+	})
+	if err != nil {
+		stats.RecordFailure(job.TelemetryType)
+		return &GRPCRequestError{Endpoint: job.Endpoint, Err: err}
+	}
+
+	stats.RecordSuccess(job.TelemetryType)
+	recordPartialSuccess(job, otlp.ResponsePartialSuccess(accepted), stats)
+	return nil
+}
+
+// Close closes every open connection
+func (gs *GRPCSender) Close() error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	var errs []error
+	for endpoint, conn := range gs.conns {
+		errs = append(errs, conn.Close())
+		delete(gs.conns, endpoint)
+	}
+	return errors.Join(errs...)
+}
+
+func (gs *GRPCSender) conn(endpoint string) (*grpc.ClientConn, error) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if conn, ok := gs.conns[endpoint]; ok {
+		return conn, nil
+	}
+
+	target, secure := GRPCTarget(endpoint)
+	creds := insecure.NewCredentials()
+	if secure || gs.forceTLS {
+		tlsConfig := gs.tlsConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if gs.ConnectTimeout > 0 {
+		opts = append(opts, grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: gs.ConnectTimeout,
+		}))
+	}
+	if gs.Compression == config.COMPRESSION_GZIP {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	}
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	gs.conns[endpoint] = conn
+	return conn, nil
+}
+
+// GRPCTarget turns an endpoint into a gRPC target. Endpoints may be given as host:port
+// or as URLs, in which case the path is dropped and https selects TLS. Setting any
+// TLS option enables TLS regardless of the endpoint form.
+func GRPCTarget(endpoint string) (string, bool) {
+	if !strings.Contains(endpoint, "://") {
+		return endpoint, false
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return endpoint, false
+	}
+	return u.Host, u.Scheme == "https"
+}
+
+// protoSize measures payloads as encoded Export*ServiceRequest messages
+func protoSize(telemetryType structs.TelemetryType) otlp.SizeFunc {
+	return func(payload any) (int, error) {
+		msg, err := otlp.ToProto(payload, telemetryType)
+		if err != nil {
+			return 0, err
+		}
+		return proto.Size(msg), nil
+	}
+}
+
+// export calls the Export RPC matching the request type, bounded by RequestTimeout, and returns its response
+func (gs *GRPCSender) export(ctx context.Context, conn *grpc.ClientConn, msg proto.Message) (proto.Message, error) {
+	if gs.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, gs.RequestTimeout)
+		defer cancel()
+	}
+	switch req := msg.(type) {
+	case *coltrace.ExportTraceServiceRequest:
+		return coltrace.NewTraceServiceClient(conn).Export(ctx, req)
+	case *collogs.ExportLogsServiceRequest:
+		return collogs.NewLogsServiceClient(conn).Export(ctx, req)
+	case *colmetrics.ExportMetricsServiceRequest:
+		return colmetrics.NewMetricsServiceClient(conn).Export(ctx, req)
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,158 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/otlp"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+func TestGRPCSenderAllTypes(t *testing.T) {
+	mock := testutil.NewMockOTelGRPCCollector()
+	defer mock.Close()
+	gs := newTestGRPCSender(t, config.NewConfig())
+	defer gs.Close()
+	st := &stats.SendStats{}
+
+	if err := gs.Send(context.Background(), mock.Endpoint(), map[string]any{"resourceSpans": []any{map[string]any{"scopeSpans": []any{}}}}, structs.TelemetryTraces, st); err != nil {
+		t.Fatalf("Send traces returned error: %v", err)
+	}
+	if err := gs.Send(context.Background(), mock.Endpoint(), map[string]any{"resourceLogs": []any{map[string]any{"scopeLogs": []any{}}}}, structs.TelemetryLogs, st); err != nil {
+		t.Fatalf("Send logs returned error: %v", err)
+	}
+	if err := gs.Send(context.Background(), "http://"+mock.Endpoint()+"/v1/metrics", map[string]any{"resourceMetrics": []any{map[string]any{"scopeMetrics": []any{}}}}, structs.TelemetryMetrics, st); err != nil {
+		t.Fatalf("Send metrics returned error: %v", err)
+	}
+
+	traces, logs, metrics, total := mock.GetStats()
+	if traces != 1 || logs != 1 || metrics != 1 || total != 3 {
+		t.Errorf("Expected one export per type, got traces=%d logs=%d metrics=%d total=%d", traces, logs, metrics, total)
+	}
+	if st.TracesSuccess != 1 || st.LogsSuccess != 1 || st.MetricsSuccess != 1 {
+		t.Errorf("Expected one success per type, got traces=%d logs=%d metrics=%d", st.TracesSuccess, st.LogsSuccess, st.MetricsSuccess)
+	}
+}
+
+func TestGRPCSenderServerFailure(t *testing.T) {
+	mock := testutil.NewMockOTelGRPCCollector()
+	defer mock.Close()
+	mock.ShouldFail = true
+	gs := newTestGRPCSender(t, config.NewConfig())
+	defer gs.Close()
+	st := &stats.SendStats{}
+
+	err := gs.Send(context.Background(), mock.Endpoint(), map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st)
+	var grpcErr *GRPCRequestError
+	if !errors.As(err, &grpcErr) {
+		t.Fatalf("Expected GRPCRequestError, got %T: %v", err, err)
+	}
+	if st.TracesFailed != 1 || st.TracesSuccess != 0 {
+		t.Errorf("Expected one failed trace, got success=%d failed=%d", st.TracesSuccess, st.TracesFailed)
+	}
+}
+
+func TestGRPCSenderConversionError(t *testing.T) {
+	gs := newTestGRPCSender(t, config.NewConfig())
+	defer gs.Close()
+	st := &stats.SendStats{}
+	err := gs.Send(context.Background(), "localhost:4317", map[string]any{"resourceSpans": "invalid"}, structs.TelemetryTraces, st)
+	var protoErr *ProtobufMarshalError
+	if !errors.As(err, &protoErr) {
+		t.Fatalf("Expected ProtobufMarshalError, got %T: %v", err, err)
+	}
+	if st.TracesFailed != 0 {
+		t.Errorf("Expected conversion errors not to count as failed sends, got %d", st.TracesFailed)
+	}
+}
+
+type grpcTargetResult struct {
+	Target string
+	Secure bool
+}
+
+func TestGRPCTarget(t *testing.T) {
+	newTest := func(endpoint string, expected grpcTargetResult) c.CharacterizationTest[grpcTargetResult] {
+		return c.NewCharacterizationTest(expected, nil, func() (grpcTargetResult, error) {
+			target, secure := GRPCTarget(endpoint)
+			return grpcTargetResult{Target: target, Secure: secure}, nil
+		})
+	}
+	tests := []c.CharacterizationTest[grpcTargetResult]{
+		newTest("localhost:4317", grpcTargetResult{Target: "localhost:4317"}),
+		newTest("http://collector:4317/v1/traces", grpcTargetResult{Target: "collector:4317"}),
+		newTest("https://collector:443", grpcTargetResult{Target: "collector:443", Secure: true}),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestNewTransport(t *testing.T) {
+	transport, _ := NewTransport(&config.Config{Protocol: config.PROTOCOL_GRPC})
+	if _, ok := transport.(*GRPCSender); !ok {
+		t.Error("Expected grpc protocol to select GRPCSender")
+	}
+	transport, _ = NewTransport(&config.Config{Protocol: config.PROTOCOL_HTTP})
+	if _, ok := transport.(*HTTPSender); !ok {
+		t.Error("Expected http protocol to select HTTPSender")
+	}
+	transport, _ = NewTransport(&config.Config{})
+	if _, ok := transport.(*HTTPSender); !ok {
+		t.Error("Expected empty protocol to default to HTTPSender")
+	}
+	transport, err := NewTransport(&config.Config{AuthMode: config.AUTH_BEARER})
+	if err == nil || transport != nil {
+		t.Errorf("Expected missing secret to fail transport creation, got %v", err)
+	}
+}
+
+func newTestGRPCSender(t *testing.T, cfg *config.Config) *GRPCSender {
+	t.Helper()
+	gs, err := NewGRPCSender(cfg)
+	if err != nil {
+		t.Fatalf("NewGRPCSender returned error: %v", err)
+	}
+	return gs
+}
+
+func TestGRPCSenderPartialSuccess(t *testing.T) {
+	mock := testutil.NewMockOTelGRPCCollector()
+	defer mock.Close()
+	mock.QueuePartialSuccesses(otlp.PartialSuccess{Rejected: 3, ErrorMessage: "dropped"})
+	gs := newTestGRPCSender(t, config.NewConfig())
+	defer gs.Close()
+	st := &stats.SendStats{}
+
+	job := structs.TelemetryJob{Endpoint: mock.Endpoint(), Payload: map[string]any{"resourceLogs": []any{}}, TelemetryType: structs.TelemetryLogs, LineNum: 7}
+	if err := gs.SendJob(context.Background(), job, st); err != nil {
+		t.Fatalf("SendJob returned error: %v", err)
+	}
+	if err := gs.SendJob(context.Background(), job, st); err != nil {
+		t.Fatalf("SendJob returned error: %v", err)
+	}
+	if st.LogsSuccess != 2 || st.LogsRejected != 3 {
+		t.Errorf("Expected 2 successes and 3 rejected log records, got success=%d rejected=%d", st.LogsSuccess, st.LogsRejected)
+	}
+}
+
+func TestGRPCSenderRecordsDispatchedItems(t *testing.T) {
+	mock := testutil.NewMockOTelGRPCCollector()
+	defer mock.Close()
+	gs := newTestGRPCSender(t, config.NewConfig())
+	defer gs.Close()
+	st := &stats.SendStats{}
+
+	payload := map[string]any{"resourceSpans": []any{map[string]any{"scopeSpans": []any{
+		map[string]any{"spans": []any{map[string]any{"name": "a"}, map[string]any{"name": "b"}, map[string]any{"name": "c"}}},
+	}}}}
+	if err := gs.Send(context.Background(), mock.Endpoint(), payload, structs.TelemetryTraces, st); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if st.TracesSpans != 3 || st.TracesBytes == 0 {
+		t.Errorf("Expected 3 spans and a non-zero byte count, got spans=%d bytes=%d", st.TracesSpans, st.TracesBytes)
+	}
+}
@@ -0,0 +1,20 @@
+package sender
+
+import (
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// resolveHeaders resolves the request headers of every telemetry type once,
+// so secrets are read a single time per run
+func resolveHeaders(cfg *config.Config) (map[structs.TelemetryType]map[string]string, error) {
+	headers := make(map[structs.TelemetryType]map[string]string)
+	for _, telemetryType := range []structs.TelemetryType{structs.TelemetryTraces, structs.TelemetryLogs, structs.TelemetryMetrics} {
+		h, err := cfg.RequestHeaders(telemetryType)
+		if err != nil {
+			return nil, err
+		}
+		headers[telemetryType] = h
+	}
+	return headers, nil
+}
@@ -0,0 +1,61 @@
+package sender
+
+import (
+	"context"
+	"testing"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+func headersTestConfig(t *testing.T) *config.Config {
+	t.Setenv("TEST_SENDER_TOKEN", "s3cr3t")
+	cfg := config.NewConfig()
+	cfg.Headers = []string{"x-tenant=team-a"}
+	cfg.MetricsHeaders = []string{"x-tenant=metrics-team"}
+	cfg.AuthMode = config.AUTH_BEARER
+	cfg.AuthSecretEnv = "TEST_SENDER_TOKEN"
+	return cfg
+}
+
+func TestHTTPSenderHeaders(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	hs := newTestHTTPSender(t, headersTestConfig(t))
+	st := &stats.SendStats{}
+
+	hs.Send(context.Background(), mock.TracesURL(), map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st)
+	hs.Send(context.Background(), mock.MetricsURL(), map[string]any{"resourceMetrics": []any{}}, structs.TelemetryMetrics, st)
+
+	if got := mock.ReceivedHeaders[0].Get("x-tenant"); got != "team-a" {
+		t.Errorf("Expected global header on traces, got %q", got)
+	}
+	if got := mock.ReceivedHeaders[1].Get("x-tenant"); got != "metrics-team" {
+		t.Errorf("Expected per-signal header on metrics, got %q", got)
+	}
+	for _, header := range mock.ReceivedHeaders {
+		if got := header.Get("Authorization"); got != "Bearer s3cr3t" {
+			t.Errorf("Expected bearer token on every request, got %q", got)
+		}
+	}
+}
+
+func TestGRPCSenderHeaders(t *testing.T) {
+	mock := testutil.NewMockOTelGRPCCollector()
+	defer mock.Close()
+	gs := newTestGRPCSender(t, headersTestConfig(t))
+	defer gs.Close()
+	st := &stats.SendStats{}
+
+	gs.Send(context.Background(), mock.Endpoint(), map[string]any{"resourceMetrics": []any{}}, structs.TelemetryMetrics, st)
+
+	md := mock.ReceivedMetadata[0]
+	if got := md.Get("x-tenant"); len(got) != 1 || got[0] != "metrics-team" {
+		t.Errorf("Expected per-signal metadata, got %v", got)
+	}
+	if got := md.Get("authorization"); len(got) != 1 || got[0] != "Bearer s3cr3t" {
+		t.Errorf("Expected bearer token metadata, got %v", got)
+	}
+}
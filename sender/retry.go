@@ -0,0 +1,172 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy controls how sends that fail with a retryable error are retried
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// NewRetryPolicy creates a RetryPolicy from cfg
+func NewRetryPolicy(cfg *config.Config) RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     cfg.MaxRetries,
+		InitialBackoff: cfg.RetryInitialBackoff,
+		MaxBackoff:     cfg.RetryMaxBackoff,
+	}
+}
+
+// attemptFunc performs a single delivery attempt. When the attempt fails it reports
+// whether the failure is retryable and how long the server asked the client to wait.
+type attemptFunc func() (retryable bool, retryAfter time.Duration, err error)
+
+// run calls attempt until it succeeds, fails with a permanent error, the retry budget is spent
+// or ctx is cancelled while waiting for the next attempt
+func (p RetryPolicy) run(ctx context.Context, telemetryType structs.TelemetryType, stats *stats.SendStats, attempt attemptFunc) error {
+	for n := 0; ; n++ {
+		retryable, retryAfter, err := attempt()
+		if err == nil || !retryable {
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if n >= p.MaxRetries {
+			if p.MaxRetries > 0 {
+				stats.RecordGiveUp(telemetryType)
+			}
+			return err
+		}
+
+		delay := p.Backoff(n)
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		// A server asking for a longer wait than the backoff allows must not park the worker
+		if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+			delay = p.MaxBackoff
+		}
+		stats.RecordRetry(telemetryType)
+		slog.Warn("Retrying telemetry send", "type", telemetryType, "attempt", n+1, "delay", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff returns the jittered exponential delay before retry number n (starting at 0).
+// The delay is drawn uniformly from the upper half of the exponential window.
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	delay := p.InitialBackoff
+	for range n {
+		delay *= 2
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			delay = p.MaxBackoff
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if delay <= 1 {
+		return delay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(delay-half)))
+}
+
+// isRetryableStatus reports whether OTLP/HTTP allows retrying a response with this status code
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableTransportError reports whether a request that got no response failed for a
+// transient network reason: a connection that was refused, reset or closed early, or a
+// timeout. Permanent failures such as certificate errors or malformed URLs are not retried.
+func isRetryableTransportError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseRetryAfter parses a Retry-After header given either as seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// grpcRetryInfo reports whether OTLP/gRPC allows retrying an error and the delay requested through RetryInfo.
+// RESOURCE_EXHAUSTED is only retryable when the server attached RetryInfo.
+func grpcRetryInfo(err error) (bool, time.Duration) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return true, 0
+	}
+
+	var retryAfter time.Duration
+	hasRetryInfo := false
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			hasRetryInfo = true
+			retryAfter = info.GetRetryDelay().AsDuration()
+		}
+	}
+
+	switch st.Code() {
+	case codes.Canceled, codes.DeadlineExceeded, codes.Aborted, codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+		return true, retryAfter
+	case codes.ResourceExhausted:
+		return hasRetryInfo, retryAfter
+	default:
+		return false, 0
+	}
+}
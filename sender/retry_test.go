@@ -0,0 +1,229 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var fastRetry = RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+// patientRetry backs off quickly but lets servers ask for waits of up to two minutes
+var patientRetry = RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Minute}
+
+type RetryResult struct {
+	Success  int
+	Failed   int
+	Retries  int
+	GaveUp   int
+	Requests int
+}
+
+func createRetryTest(responses []testutil.MockResponse, expected RetryResult) c.CharacterizationTest[RetryResult] {
+	return c.NewCharacterizationTest(
+		expected,
+		nil,
+		func() (RetryResult, error) {
+			mock := testutil.NewMockOTelCollector()
+			defer mock.Close()
+			mock.QueueResponses(responses...)
+			st := &stats.SendStats{}
+			hs := &HTTPSender{Retry: fastRetry}
+			hs.Send(context.Background(), mock.TracesURL(), map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st)
+			_, _, _, total := mock.GetStats()
+			return RetryResult{
+				Success:  st.TracesSuccess,
+				Failed:   st.TracesFailed,
+				Retries:  st.TracesRetries,
+				GaveUp:   st.TracesGaveUp,
+				Requests: total,
+			}, nil
+		},
+	)
+}
+
+func TestHTTPSenderRetry(t *testing.T) {
+	unavailable := testutil.MockResponse{StatusCode: http.StatusServiceUnavailable}
+	tests := []c.CharacterizationTest[RetryResult]{
+		createRetryTest(
+			[]testutil.MockResponse{unavailable, {StatusCode: http.StatusTooManyRequests}},
+			RetryResult{Success: 1, Retries: 2, Requests: 3},
+		),
+		createRetryTest(
+			[]testutil.MockResponse{{StatusCode: http.StatusBadGateway}, {StatusCode: http.StatusGatewayTimeout}},
+			RetryResult{Success: 1, Retries: 2, Requests: 3},
+		),
+		createRetryTest(
+			[]testutil.MockResponse{unavailable, unavailable, unavailable, unavailable},
+			RetryResult{Failed: 1, Retries: 3, GaveUp: 1, Requests: 4},
+		),
+		createRetryTest(
+			[]testutil.MockResponse{{StatusCode: http.StatusBadRequest}},
+			RetryResult{Failed: 1, Requests: 1},
+		),
+		createRetryTest(
+			[]testutil.MockResponse{{StatusCode: http.StatusInternalServerError}},
+			RetryResult{Failed: 1, Requests: 1},
+		),
+		createRetryTest(
+			[]testutil.MockResponse{{CloseConnection: true}},
+			RetryResult{Success: 1, Retries: 1, Requests: 2},
+		),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestHTTPSenderTransportErrorRetries(t *testing.T) {
+	tlsMock := testutil.NewMockOTelTLSCollector(nil)
+	defer tlsMock.Close()
+	closed := testutil.NewMockOTelCollector()
+	refusedURL := closed.TracesURL()
+	closed.Close()
+
+	for _, tc := range []struct {
+		name    string
+		url     string
+		retries int
+	}{
+		{name: "connection refused", url: refusedURL, retries: fastRetry.MaxRetries},
+		{name: "untrusted certificate", url: tlsMock.TracesURL(), retries: 0},
+		{name: "unsupported scheme", url: "ftp://localhost/v1/traces", retries: 0},
+	} {
+		st := &stats.SendStats{}
+		hs := &HTTPSender{Retry: fastRetry}
+		if err := hs.Send(context.Background(), tc.url, map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+		if st.TracesRetries != tc.retries || st.TracesFailed != 1 {
+			t.Errorf("%s: expected %d retries and 1 failure, got retries=%d failed=%d", tc.name, tc.retries, st.TracesRetries, st.TracesFailed)
+		}
+	}
+}
+
+func TestHTTPSenderHonorsRetryAfter(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	mock.QueueResponses(testutil.MockResponse{StatusCode: http.StatusTooManyRequests, RetryAfter: "1"})
+	st := &stats.SendStats{}
+	hs := &HTTPSender{Retry: patientRetry}
+
+	start := time.Now()
+	if err := hs.Send(context.Background(), mock.TracesURL(), map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Expected Retry-After of 1s to be honored, retried after %v", elapsed)
+	}
+	if st.TracesSuccess != 1 || st.TracesRetries != 1 {
+		t.Errorf("Expected 1 success after 1 retry, got success=%d retries=%d", st.TracesSuccess, st.TracesRetries)
+	}
+}
+
+func TestRetryPolicyCapsRetryAfter(t *testing.T) {
+	attempts := 0
+	start := time.Now()
+	err := fastRetry.run(context.Background(), structs.TelemetryTraces, &stats.SendStats{}, func() (bool, time.Duration, error) {
+		attempts++
+		if attempts == 1 {
+			return true, 24 * time.Hour, errors.New("busy")
+		}
+		return false, 0, nil
+	})
+	if err != nil || attempts != 2 {
+		t.Fatalf("Expected success on the second attempt, got attempts=%d err=%v", attempts, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Retry-After to be capped at the maximum backoff, waited %v", elapsed)
+	}
+}
+
+func TestHTTPSenderNoRetriesConfigured(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	mock.QueueResponses(testutil.MockResponse{StatusCode: http.StatusServiceUnavailable})
+	st := &stats.SendStats{}
+	err := SendToOTel(context.Background(), mock.TracesURL(), map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st)
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected HTTPStatusError with status 503, got %T: %v", err, err)
+	}
+	if st.TracesFailed != 1 || st.TracesRetries != 0 || st.TracesGaveUp != 0 {
+		t.Errorf("Expected a single failed attempt, got failed=%d retries=%d gave_up=%d", st.TracesFailed, st.TracesRetries, st.TracesGaveUp)
+	}
+}
+
+func TestGRPCSenderRetry(t *testing.T) {
+	mock := testutil.NewMockOTelGRPCCollector()
+	defer mock.Close()
+	gs := newTestGRPCSender(t, config.NewConfig())
+	gs.Retry = fastRetry
+	defer gs.Close()
+
+	throttled, _ := status.New(codes.ResourceExhausted, "slow down").WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Millisecond)})
+	mock.QueueErrors(status.Error(codes.Unavailable, "unavailable"), throttled.Err())
+	st := &stats.SendStats{}
+	if err := gs.Send(context.Background(), mock.Endpoint(), map[string]any{"resourceLogs": []any{}}, structs.TelemetryLogs, st); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if st.LogsSuccess != 1 || st.LogsRetries != 2 {
+		t.Errorf("Expected success after 2 retries, got success=%d retries=%d", st.LogsSuccess, st.LogsRetries)
+	}
+
+	mock.QueueErrors(status.Error(codes.ResourceExhausted, "quota"))
+	st = &stats.SendStats{}
+	if err := gs.Send(context.Background(), mock.Endpoint(), map[string]any{"resourceLogs": []any{}}, structs.TelemetryLogs, st); err == nil {
+		t.Fatal("Expected RESOURCE_EXHAUSTED without RetryInfo to fail")
+	}
+	if st.LogsFailed != 1 || st.LogsRetries != 0 {
+		t.Errorf("Expected no retries, got failed=%d retries=%d", st.LogsFailed, st.LogsRetries)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 400 * time.Millisecond}
+	bounds := []struct{ min, max time.Duration }{
+		{50 * time.Millisecond, 100 * time.Millisecond},
+		{100 * time.Millisecond, 200 * time.Millisecond},
+		{200 * time.Millisecond, 400 * time.Millisecond},
+		{200 * time.Millisecond, 400 * time.Millisecond},
+		{200 * time.Millisecond, 400 * time.Millisecond},
+	}
+	for n, b := range bounds {
+		for range 20 {
+			if d := policy.Backoff(n); d < b.min || d > b.max {
+				t.Errorf("Backoff(%d) = %v, want between %v and %v", n, d, b.min, b.max)
+			}
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []c.CharacterizationTest[time.Duration]{
+		c.NewCharacterizationTest(5*time.Second, nil, func() (time.Duration, error) {
+			return parseRetryAfter("5", now), nil
+		}),
+		c.NewCharacterizationTest(30*time.Second, nil, func() (time.Duration, error) {
+			return parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now), nil
+		}),
+		c.NewCharacterizationTest(time.Duration(0), nil, func() (time.Duration, error) {
+			return parseRetryAfter("", now), nil
+		}),
+		c.NewCharacterizationTest(time.Duration(0), nil, func() (time.Duration, error) {
+			return parseRetryAfter("soon", now), nil
+		}),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
@@ -2,36 +2,179 @@ package sender
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"io"
 	"log/slog"
+	"mime"
 	"net/http"
+	"time"
 
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/otlp"
 	"github.com/laiambryant/telemetry-ingestor/stats"
 	"github.com/laiambryant/telemetry-ingestor/structs"
 )
 
-// sendToOTel sends telemetry data to the OpenTelemetry collector
-func SendToOTel(endpoint string, payload any, telemetryType structs.TelemetryType, stats *stats.SendStats) error {
-	jsonData, err := json.Marshal(payload)
+// HTTPSender sends telemetry data to an OpenTelemetry collector over OTLP/HTTP
+type HTTPSender struct {
+	Encoding    string
+	Compression string
+	Retry       RetryPolicy
+	Headers     map[structs.TelemetryType]map[string]string
+	// MaxRequestBytes splits payloads whose encoded size exceeds it, 0 disables splitting
+	MaxRequestBytes int
+	// Client performs the requests, http.DefaultClient is used when nil
+	Client *http.Client
+}
+
+// NewHTTPSender creates an HTTPSender configured from cfg with a dedicated client built by NewHTTPClient
+func NewHTTPSender(cfg *config.Config) (*HTTPSender, error) {
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewHTTPSenderWithClient(cfg, client)
+}
+
+// NewHTTPSenderWithClient creates an HTTPSender configured from cfg that sends through client
+func NewHTTPSenderWithClient(cfg *config.Config, client *http.Client) (*HTTPSender, error) {
+	headers, err := resolveHeaders(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPSender{
+		Encoding:        cfg.OTLPEncoding,
+		Compression:     cfg.Compression,
+		Retry:           NewRetryPolicy(cfg),
+		Headers:         headers,
+		MaxRequestBytes: cfg.MaxRequestBytes,
+		Client:          client,
+	}, nil
+}
+
+// SendToOTel sends telemetry data to the OpenTelemetry collector using OTLP/JSON
+func SendToOTel(ctx context.Context, endpoint string, payload any, telemetryType structs.TelemetryType, stats *stats.SendStats) error {
+	return (&HTTPSender{Encoding: config.ENCODING_JSON}).Send(ctx, endpoint, payload, telemetryType, stats)
+}
+
+// Send encodes the payload and posts it to the endpoint, retrying retryable failures
+func (hs *HTTPSender) Send(ctx context.Context, endpoint string, payload any, telemetryType structs.TelemetryType, stats *stats.SendStats) error {
+	return hs.SendJob(ctx, structs.TelemetryJob{Endpoint: endpoint, Payload: payload, TelemetryType: telemetryType}, stats)
+}
+
+// SendJob encodes the job payload and posts it to the job endpoint, retrying retryable failures.
+// Cancelling ctx aborts the request in flight and any pending retry.
+func (hs *HTTPSender) SendJob(ctx context.Context, job structs.TelemetryJob, stats *stats.SendStats) error {
+	data, contentType, err := encodePayload(job.Payload, job.TelemetryType, hs.Encoding)
+	if err != nil {
+		return err
+	}
+	if hs.MaxRequestBytes > 0 && len(data) > hs.MaxRequestBytes {
+		return sendSplit(ctx, job, hs.MaxRequestBytes, hs.encodedSize(job.TelemetryType), stats, hs.SendJob)
+	}
+	encodedBytes := int64(len(data))
+	data, contentEncoding, err := compressBody(data, hs.Compression)
+	if err != nil {
+		return err
+	}
+	body := requestBody{data: data, contentType: contentType, contentEncoding: contentEncoding}
+	stats.RecordDispatched(job.TelemetryType, otlp.CountItems(job.Payload, job.TelemetryType), encodedBytes)
+
+	var accepted *response
+	err = hs.Retry.run(ctx, job.TelemetryType, stats, func() (bool, time.Duration, error) {
+		resp, err := hs.post(ctx, job.Endpoint, hs.Headers[job.TelemetryType], body)
+		if err != nil {
+			return isRetryableTransportError(err), 0, err
+		}
+		if resp.statusCode == 200 {
+			accepted = resp
+			return false, 0, nil
+		}
+		statusErr := &HTTPStatusError{Endpoint: job.Endpoint, StatusCode: resp.statusCode, Body: string(resp.body)}
+		return isRetryableStatus(resp.statusCode), resp.retryAfter, statusErr
+	})
+	if err == nil {
+		stats.RecordSuccess(job.TelemetryType)
+		partial, err := otlp.DecodeResponse(accepted.body, accepted.contentType, job.TelemetryType)
+		if err != nil {
+			slog.Warn("Failed to decode collector response", "type", job.TelemetryType, "line", job.LineNum, "error", err)
+			return nil
+		}
+		recordPartialSuccess(job, partial, stats)
+		return nil
+	}
+
+	stats.RecordFailure(job.TelemetryType)
+	if statusErr, ok := err.(*HTTPStatusError); ok {
+		return statusErr
+	}
+	return &HTTPRequestError{Endpoint: job.Endpoint, Err: err}
+}
+
+// encodedSize measures payloads as encoded by the sender, before compression
+func (hs *HTTPSender) encodedSize(telemetryType structs.TelemetryType) otlp.SizeFunc {
+	return func(payload any) (int, error) {
+		data, _, err := encodePayload(payload, telemetryType, hs.Encoding)
+		return len(data), err
+	}
+}
+
+// requestBody is an encoded, possibly compressed, OTLP/HTTP request body
+type requestBody struct {
+	data            []byte
+	contentType     string
+	contentEncoding string
+}
+
+// response is the outcome of a single OTLP/HTTP request
+type response struct {
+	statusCode  int
+	contentType string
+	body        []byte
+	retryAfter  time.Duration
+}
+
+// post performs a single OTLP/HTTP request
+func (hs *HTTPSender) post(ctx context.Context, endpoint string, headers map[string]string, body requestBody) (*response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body.data))
 	if err != nil {
-		return &JSONMarshalError{Err: err}
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", body.contentType)
+	if body.contentEncoding != "" {
+		req.Header.Set("Content-Encoding", body.contentEncoding)
 	}
 
-	resp, err := http.Post(endpoint, "application/json", bytes.NewBuffer(jsonData))
+	client := hs.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
 	if err != nil {
-		stats.RecordFailure(telemetryType)
-		return &HTTPRequestError{Endpoint: endpoint, Err: err}
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == 200 {
-		stats.RecordSuccess(telemetryType)
-	} else {
-		stats.RecordFailure(telemetryType)
-		body, _ := io.ReadAll(resp.Body)
-		slog.Error("Failed to send telemetry", "type", telemetryType, "status", resp.StatusCode, "response", string(body))
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
 	}
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return &response{
+		statusCode:  resp.StatusCode,
+		contentType: contentType,
+		body:        respBody,
+		retryAfter:  parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}, nil
+}
 
+// Close releases idle connections held by the sender
+func (hs *HTTPSender) Close() error {
+	if hs.Client != nil {
+		hs.Client.CloseIdleConnections()
+	}
 	return nil
 }
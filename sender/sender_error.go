@@ -1,6 +1,10 @@
 package sender
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/laiambryant/telemetry-ingestor/structs"
+)
 
 // JSONMarshalError represents an error when marshaling JSON fails
 type JSONMarshalError struct {
@@ -28,3 +32,96 @@ func (e *HTTPRequestError) Error() string {
 func (e *HTTPRequestError) Unwrap() error {
 	return e.Err
 }
+
+// ProtobufMarshalError represents an error when encoding a payload as OTLP protobuf fails
+type ProtobufMarshalError struct {
+	Err error
+}
+
+func (e *ProtobufMarshalError) Error() string {
+	return fmt.Sprintf("failed to marshal protobuf: %v", e.Err)
+}
+
+func (e *ProtobufMarshalError) Unwrap() error {
+	return e.Err
+}
+
+// GRPCRequestError represents an error when an OTLP/gRPC export call fails
+type GRPCRequestError struct {
+	Endpoint string
+	Err      error
+}
+
+func (e *GRPCRequestError) Error() string {
+	return fmt.Sprintf("failed to export to %s: %v", e.Endpoint, e.Err)
+}
+
+func (e *GRPCRequestError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatusError represents a collector response with a non-success status code
+type HTTPStatusError struct {
+	Endpoint   string
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("collector at %s responded with status %d: %s", e.Endpoint, e.StatusCode, e.Body)
+}
+
+// CompressionError represents an error when compressing a request body fails
+type CompressionError struct {
+	Compression string
+	Err         error
+}
+
+func (e *CompressionError) Error() string {
+	return fmt.Sprintf("failed to compress body with %s: %v", e.Compression, e.Err)
+}
+
+func (e *CompressionError) Unwrap() error {
+	return e.Err
+}
+
+// TLSConfigError represents an invalid TLS option
+type TLSConfigError struct {
+	Option string
+	Path   string
+	Err    error
+}
+
+func (e *TLSConfigError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("invalid --%s: %v", e.Option, e.Err)
+	}
+	return fmt.Sprintf("invalid --%s %s: %v", e.Option, e.Path, e.Err)
+}
+
+func (e *TLSConfigError) Unwrap() error {
+	return e.Err
+}
+
+// JobError ties a send failure to the job, or the part of a split job, that failed
+type JobError struct {
+	Job structs.TelemetryJob
+	Err error
+}
+
+func (e *JobError) Error() string {
+	return fmt.Sprintf("failed to send %s from line %d: %v", e.Job.TelemetryType, e.Job.LineNum, e.Err)
+}
+
+func (e *JobError) Unwrap() error {
+	return e.Err
+}
+
+// CircuitOpenError represents a job that was not sent because the circuit breaker of its endpoint is open
+type CircuitOpenError struct {
+	Endpoint string
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for %s is open", e.Endpoint)
+}
@@ -1,14 +1,21 @@
 package sender
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"testing"
 
 	c "github.com/laiambryant/gotestutils/ctesting"
+	"github.com/laiambryant/telemetry-ingestor/config"
 	"github.com/laiambryant/telemetry-ingestor/stats"
 	"github.com/laiambryant/telemetry-ingestor/structs"
 	"github.com/laiambryant/telemetry-ingestor/testutil"
+	colmetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestJSONMarshalErrorInterface(t *testing.T) {
@@ -119,19 +126,19 @@ func TestSendToOTelServerFailure(t *testing.T) {
 		structs.TelemetryTraces,
 		map[string]any{"resourceSpans": []any{}},
 		true,
-		SendResult{Success: false, Failed: true, Error: false},
+		SendResult{Success: false, Failed: true, Error: true},
 	)
 	test2 := createSendTest(
 		structs.TelemetryLogs,
 		map[string]any{"resourceLogs": []any{}},
 		true,
-		SendResult{Success: false, Failed: true, Error: false},
+		SendResult{Success: false, Failed: true, Error: true},
 	)
 	test3 := createSendTest(
 		structs.TelemetryMetrics,
 		map[string]any{"resourceMetrics": []any{}},
 		true,
-		SendResult{Success: false, Failed: true, Error: false},
+		SendResult{Success: false, Failed: true, Error: true},
 	)
 	tests := []c.CharacterizationTest[SendResult]{test1, test2, test3}
 	c.VerifyCharacterizationTestsAndResults(t, tests, false)
@@ -144,7 +151,7 @@ func TestSendToOTelInvalidEndpoint(t *testing.T) {
 		func() (bool, error) {
 			st := &stats.SendStats{}
 			payload := map[string]any{"resourceSpans": []any{}}
-			err := SendToOTel("http://127.0.0.1:0/v1/traces", payload, structs.TelemetryTraces, st)
+			err := SendToOTel(context.Background(), "http://127.0.0.1:0/v1/traces", payload, structs.TelemetryTraces, st)
 			if err == nil {
 				return false, nil
 			}
@@ -171,7 +178,7 @@ func TestSendToOTelUnmarshalablePayload(t *testing.T) {
 			payload := map[string]any{
 				"unmarshalable": make(chan int),
 			}
-			err := SendToOTel(mock.TracesURL(), payload, structs.TelemetryTraces, st)
+			err := SendToOTel(context.Background(), mock.TracesURL(), payload, structs.TelemetryTraces, st)
 			if err == nil {
 				return false, nil
 			}
@@ -198,15 +205,15 @@ func TestSendToOTelMultipleRequests(t *testing.T) {
 
 			st := &stats.SendStats{}
 			tracesPayload := map[string]any{"resourceSpans": []any{}}
-			if err := SendToOTel(mock.TracesURL(), tracesPayload, structs.TelemetryTraces, st); err != nil {
+			if err := SendToOTel(context.Background(), mock.TracesURL(), tracesPayload, structs.TelemetryTraces, st); err != nil {
 				return false, err
 			}
 			logsPayload := map[string]any{"resourceLogs": []any{}}
-			if err := SendToOTel(mock.LogsURL(), logsPayload, structs.TelemetryLogs, st); err != nil {
+			if err := SendToOTel(context.Background(), mock.LogsURL(), logsPayload, structs.TelemetryLogs, st); err != nil {
 				return false, err
 			}
 			metricsPayload := map[string]any{"resourceMetrics": []any{}}
-			if err := SendToOTel(mock.MetricsURL(), metricsPayload, structs.TelemetryMetrics, st); err != nil {
+			if err := SendToOTel(context.Background(), mock.MetricsURL(), metricsPayload, structs.TelemetryMetrics, st); err != nil {
 				return false, err
 			}
 			return st.TracesSuccess == 1 && st.LogsSuccess == 1 && st.MetricsSuccess == 1 &&
@@ -226,12 +233,12 @@ func TestSendToOTelMixedSuccessFailure(t *testing.T) {
 			defer mock.Close()
 			st := &stats.SendStats{}
 			payload1 := map[string]any{"resourceSpans": []any{}}
-			if err := SendToOTel(mock.TracesURL(), payload1, structs.TelemetryTraces, st); err != nil {
+			if err := SendToOTel(context.Background(), mock.TracesURL(), payload1, structs.TelemetryTraces, st); err != nil {
 				return false, err
 			}
 			mock.ShouldFail = true
 			payload2 := map[string]any{"resourceLogs": []any{}}
-			SendToOTel(mock.LogsURL(), payload2, structs.TelemetryLogs, st)
+			SendToOTel(context.Background(), mock.LogsURL(), payload2, structs.TelemetryLogs, st)
 			return st.TracesSuccess == 1 && st.LogsFailed == 1 &&
 				st.TracesFailed == 0 && st.LogsSuccess == 0, nil
 		},
@@ -249,7 +256,7 @@ func TestSendToOTelEmptyPayload(t *testing.T) {
 			defer mock.Close()
 			st := &stats.SendStats{}
 			payload := map[string]any{}
-			err := SendToOTel(mock.TracesURL(), payload, structs.TelemetryTraces, st)
+			err := SendToOTel(context.Background(), mock.TracesURL(), payload, structs.TelemetryTraces, st)
 			return err == nil && st.TracesSuccess == 1, nil
 		},
 	)
@@ -289,7 +296,7 @@ func TestSendToOTelComplexPayload(t *testing.T) {
 					},
 				},
 			}
-			err := SendToOTel(mock.TracesURL(), payload, structs.TelemetryTraces, st)
+			err := SendToOTel(context.Background(), mock.TracesURL(), payload, structs.TelemetryTraces, st)
 			return err == nil && st.TracesSuccess == 1 && st.TracesFailed == 0, nil
 		},
 	)
@@ -339,7 +346,7 @@ func TestStatsThreadSafety(t *testing.T) {
 	done := make(chan bool)
 	for range 10 {
 		go func() {
-			SendToOTel(mock.TracesURL(), payload, structs.TelemetryTraces, st)
+			SendToOTel(context.Background(), mock.TracesURL(), payload, structs.TelemetryTraces, st)
 			done <- true
 		}()
 	}
@@ -355,6 +362,75 @@ func TestStatsThreadSafety(t *testing.T) {
 	}
 }
 
+func TestHTTPSenderProtobufEncoding(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	st := &stats.SendStats{}
+	hs := newTestHTTPSender(t, &config.Config{OTLPEncoding: config.ENCODING_PROTOBUF})
+	payloads := []struct {
+		endpoint      string
+		payload       map[string]any
+		telemetryType structs.TelemetryType
+	}{
+		{mock.TracesURL(), map[string]any{"resourceSpans": []any{map[string]any{"scopeSpans": []any{map[string]any{"spans": []any{map[string]any{"traceId": "5b8efff798038103d269b633813fc60c", "spanId": "eee19b7ec3c1b174", "name": "span"}}}}}}}, structs.TelemetryTraces},
+		{mock.LogsURL(), map[string]any{"resourceLogs": []any{map[string]any{"scopeLogs": []any{}}}}, structs.TelemetryLogs},
+		{mock.MetricsURL(), map[string]any{"resourceMetrics": []any{map[string]any{"scopeMetrics": []any{}}}}, structs.TelemetryMetrics},
+	}
+	for _, p := range payloads {
+		if err := hs.Send(context.Background(), p.endpoint, p.payload, p.telemetryType, st); err != nil {
+			t.Fatalf("Send returned error: %v", err)
+		}
+	}
+	if st.TracesSuccess != 1 || st.LogsSuccess != 1 || st.MetricsSuccess != 1 {
+		t.Errorf("Expected one success per type, got traces=%d logs=%d metrics=%d", st.TracesSuccess, st.LogsSuccess, st.MetricsSuccess)
+	}
+	for _, header := range mock.ReceivedHeaders {
+		if ct := header.Get("Content-Type"); ct != "application/x-protobuf" {
+			t.Errorf("Expected Content-Type application/x-protobuf, got %s", ct)
+		}
+	}
+	span := mock.ReceivedTraces[0]["resourceSpans"].([]any)[0].(map[string]any)["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	if span["traceId"] != "5b8efff798038103d269b633813fc60c" {
+		t.Errorf("Expected collector to decode traceId, got %v", span["traceId"])
+	}
+}
+
+func TestHTTPSenderProtobufConversionError(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	st := &stats.SendStats{}
+	hs := newTestHTTPSender(t, &config.Config{OTLPEncoding: config.ENCODING_PROTOBUF})
+	err := hs.Send(context.Background(), mock.TracesURL(), map[string]any{"resourceSpans": "invalid"}, structs.TelemetryTraces, st)
+	var protoErr *ProtobufMarshalError
+	if !errors.As(err, &protoErr) {
+		t.Fatalf("Expected ProtobufMarshalError, got %T: %v", err, err)
+	}
+	if _, _, _, total := mock.GetStats(); total != 0 {
+		t.Errorf("Expected no request to reach the collector, got %d", total)
+	}
+}
+
+func TestHTTPSenderJSONContentType(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	st := &stats.SendStats{}
+	if err := newTestHTTPSender(t, config.NewConfig()).Send(context.Background(), mock.LogsURL(), map[string]any{"resourceLogs": []any{}}, structs.TelemetryLogs, st); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if ct := mock.ReceivedHeaders[0].Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected Content-Type application/json, got %s", ct)
+	}
+}
+
+func newTestHTTPSender(t *testing.T, cfg *config.Config) *HTTPSender {
+	t.Helper()
+	hs, err := NewHTTPSender(cfg)
+	if err != nil {
+		t.Fatalf("NewHTTPSender returned error: %v", err)
+	}
+	return hs
+}
+
 func createSendTest(telemetryType structs.TelemetryType, payload map[string]any, shouldFail bool, expected SendResult) c.CharacterizationTest[SendResult] {
 	return c.NewCharacterizationTest(
 		expected,
@@ -373,7 +449,7 @@ func createSendTest(telemetryType structs.TelemetryType, payload map[string]any,
 			case structs.TelemetryMetrics:
 				endpoint = mock.MetricsURL()
 			}
-			err := SendToOTel(endpoint, payload, telemetryType, st)
+			err := SendToOTel(context.Background(), endpoint, payload, telemetryType, st)
 			var success, failed bool
 			switch telemetryType {
 			case structs.TelemetryTraces:
@@ -394,3 +470,83 @@ func createSendTest(telemetryType structs.TelemetryType, payload map[string]any,
 		},
 	)
 }
+
+func TestHTTPSenderPartialSuccess(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	mock.QueueResponses(
+		testutil.MockResponse{StatusCode: http.StatusOK, ContentType: "application/json", Body: `{"partialSuccess":{"rejectedSpans":"2","errorMessage":"invalid span kind"}}`},
+		testutil.MockResponse{StatusCode: http.StatusOK, ContentType: "application/json", Body: `{"partialSuccess":{}}`},
+	)
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{})))
+
+	hs := newTestHTTPSender(t, config.NewConfig())
+	st := &stats.SendStats{}
+	job := structs.TelemetryJob{Endpoint: mock.TracesURL(), Payload: map[string]any{"resourceSpans": []any{}}, TelemetryType: structs.TelemetryTraces, LineNum: 42}
+	if err := hs.SendJob(context.Background(), job, st); err != nil {
+		t.Fatalf("SendJob returned error: %v", err)
+	}
+	if err := hs.SendJob(context.Background(), job, st); err != nil {
+		t.Fatalf("SendJob returned error: %v", err)
+	}
+
+	if st.TracesSuccess != 2 || st.TracesRejected != 2 {
+		t.Errorf("Expected 2 successes and 2 rejected spans, got success=%d rejected=%d", st.TracesSuccess, st.TracesRejected)
+	}
+	for _, expected := range []string{"line=42", "rejected=2", `error_message="invalid span kind"`} {
+		if !bytes.Contains(buf.Bytes(), []byte(expected)) {
+			t.Errorf("Log output missing %s: %s", expected, buf.String())
+		}
+	}
+	if n := bytes.Count(buf.Bytes(), []byte("partially rejected")); n != 1 {
+		t.Errorf("Expected exactly one partial success warning, got %d", n)
+	}
+}
+
+func TestHTTPSenderPartialSuccessProtobuf(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	body, err := proto.Marshal(&colmetrics.ExportMetricsServiceResponse{
+		PartialSuccess: &colmetrics.ExportMetricsPartialSuccess{RejectedDataPoints: 5, ErrorMessage: "out of order"},
+	})
+	if err != nil {
+		t.Fatalf("proto.Marshal returned error: %v", err)
+	}
+	mock.QueueResponses(testutil.MockResponse{StatusCode: http.StatusOK, ContentType: "application/x-protobuf", Body: string(body)})
+
+	cfg := config.NewConfig()
+	cfg.OTLPEncoding = config.ENCODING_PROTOBUF
+	hs := newTestHTTPSender(t, cfg)
+	st := &stats.SendStats{}
+	if err := hs.Send(context.Background(), mock.MetricsURL(), map[string]any{"resourceMetrics": []any{}}, structs.TelemetryMetrics, st); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if st.MetricsSuccess != 1 || st.MetricsRejected != 5 {
+		t.Errorf("Expected 1 success and 5 rejected data points, got success=%d rejected=%d", st.MetricsSuccess, st.MetricsRejected)
+	}
+}
+
+func TestHTTPSenderRecordsDispatchedItems(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	cfg := config.NewConfig()
+	cfg.Compression = config.COMPRESSION_GZIP
+	hs := newTestHTTPSender(t, cfg)
+	st := &stats.SendStats{}
+
+	payload := map[string]any{"resourceLogs": []any{map[string]any{"scopeLogs": []any{
+		map[string]any{"logRecords": []any{map[string]any{"body": map[string]any{"stringValue": "a"}}, map[string]any{"body": map[string]any{"stringValue": "b"}}}},
+	}}}}
+	if err := hs.Send(context.Background(), mock.LogsURL(), payload, structs.TelemetryLogs, st); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	data, _, _ := encodePayload(payload, structs.TelemetryLogs, config.ENCODING_JSON)
+	if st.LogsRecords != 2 {
+		t.Errorf("Expected 2 log records, got %d", st.LogsRecords)
+	}
+	if st.LogsBytes != int64(len(data)) {
+		t.Errorf("Expected %d encoded bytes before compression, got %d", len(data), st.LogsBytes)
+	}
+}
@@ -0,0 +1,45 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/laiambryant/telemetry-ingestor/otlp"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// sendSplit splits a job whose encoded payload exceeds maxBytes into several jobs under
+// the limit and sends each of them with send. The failure of each part is reported as a
+// JobError. An item too large to fit on its own is not sent, counts as failed and is
+// reported as a JobError wrapping an OversizedItemError, while the rest of the job is sent.
+func sendSplit(ctx context.Context, job structs.TelemetryJob, maxBytes int, size otlp.SizeFunc, stats *stats.SendStats, send func(context.Context, structs.TelemetryJob, *stats.SendStats) error) error {
+	pieces, oversized, err := otlp.SplitPayload(job.Payload, job.TelemetryType, maxBytes, size)
+	if err != nil {
+		stats.RecordFailure(job.TelemetryType)
+		slog.Error("Telemetry exceeds the request size limit and cannot be split", "type", job.TelemetryType, "line", job.LineNum, "max_request_bytes", maxBytes, "error", err)
+		return err
+	}
+
+	var errs []error
+	for _, item := range oversized {
+		stats.RecordFailure(job.TelemetryType)
+		slog.Error("Telemetry item exceeds the request size limit on its own and was not sent", "type", job.TelemetryType, "line", job.LineNum, "size", item.Size, "max_request_bytes", maxBytes)
+		part := job
+		part.Payload = item.Payload
+		errs = append(errs, &JobError{Job: part, Err: item})
+	}
+
+	if len(pieces) > 0 {
+		slog.Info("Split oversized telemetry into several requests", "type", job.TelemetryType, "line", job.LineNum, "requests", len(pieces))
+	}
+	for _, piece := range pieces {
+		part := job
+		part.Payload = piece
+		if err := send(ctx, part, stats); err != nil {
+			errs = append(errs, &JobError{Job: part, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,134 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/otlp"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+func largeTracePayload(spans int) map[string]any {
+	items := make([]any, spans)
+	for i := range items {
+		items[i] = map[string]any{"name": fmt.Sprintf("span-%03d", i), "traceId": "5b8efff798038103d269b633813fc60c", "spanId": "eee19b7ec3c1b174"}
+	}
+	return map[string]any{"resourceSpans": []any{map[string]any{"scopeSpans": []any{map[string]any{"spans": items}}}}}
+}
+
+func TestHTTPSenderSplitsOversizedPayload(t *testing.T) {
+	for _, encoding := range []string{config.ENCODING_JSON, config.ENCODING_PROTOBUF} {
+		t.Run(encoding, func(t *testing.T) {
+			mock := testutil.NewMockOTelCollector()
+			defer mock.Close()
+			cfg := config.NewConfig()
+			cfg.OTLPEncoding = encoding
+			cfg.MaxRequestBytes = 1024
+			hs := newTestHTTPSender(t, cfg)
+			st := &stats.SendStats{}
+
+			if err := hs.Send(context.Background(), mock.TracesURL(), largeTracePayload(40), structs.TelemetryTraces, st); err != nil {
+				t.Fatalf("Send returned error: %v", err)
+			}
+			traces, _, _, _ := mock.GetStats()
+			if traces < 2 {
+				t.Fatalf("Expected the payload to be split into several requests, got %d", traces)
+			}
+			spans := 0
+			for _, received := range mock.ReceivedTraces {
+				spans += otlp.CountItems(received, structs.TelemetryTraces)
+			}
+			if spans != 40 || st.TracesSpans != 40 {
+				t.Errorf("Expected 40 spans delivered and counted, got delivered=%d counted=%d", spans, st.TracesSpans)
+			}
+			if st.TracesSuccess != traces || st.TracesFailed != 0 {
+				t.Errorf("Expected one success per request, got success=%d failed=%d requests=%d", st.TracesSuccess, st.TracesFailed, traces)
+			}
+			if st.TracesBytes > int64(traces*1024) {
+				t.Errorf("Expected every request under the limit, got %d bytes over %d requests", st.TracesBytes, traces)
+			}
+		})
+	}
+}
+
+func TestHTTPSenderOversizedItem(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	cfg := config.NewConfig()
+	cfg.MaxRequestBytes = 256
+	hs := newTestHTTPSender(t, cfg)
+	st := &stats.SendStats{}
+
+	payload := map[string]any{"resourceLogs": []any{map[string]any{"scopeLogs": []any{map[string]any{"logRecords": []any{
+		map[string]any{"body": map[string]any{"stringValue": strings.Repeat("x", 1024)}},
+	}}}}}}
+	err := hs.SendJob(context.Background(), structs.TelemetryJob{Endpoint: mock.LogsURL(), Payload: payload, TelemetryType: structs.TelemetryLogs, LineNum: 3}, st)
+	var oversized *otlp.OversizedItemError
+	if !errors.As(err, &oversized) {
+		t.Fatalf("Expected OversizedItemError, got %T: %v", err, err)
+	}
+	if _, _, _, total := mock.GetStats(); total != 0 {
+		t.Errorf("Expected nothing to be sent, got %d requests", total)
+	}
+	if st.LogsFailed != 1 {
+		t.Errorf("Expected the oversized job to count as failed, got %d", st.LogsFailed)
+	}
+}
+
+func TestHTTPSenderSendsItemsAroundOversizedItem(t *testing.T) {
+	mock := testutil.NewMockOTelCollector()
+	defer mock.Close()
+	cfg := config.NewConfig()
+	cfg.MaxRequestBytes = 1024
+	hs := newTestHTTPSender(t, cfg)
+	st := &stats.SendStats{}
+
+	payload := largeTracePayload(40)
+	spans := payload["resourceSpans"].([]any)[0].(map[string]any)["scopeSpans"].([]any)[0].(map[string]any)
+	spans["spans"] = append(spans["spans"].([]any), map[string]any{"name": strings.Repeat("x", 2048)})
+	job := structs.TelemetryJob{Endpoint: mock.TracesURL(), Payload: payload, TelemetryType: structs.TelemetryTraces, LineNum: 7}
+	err := hs.SendJob(context.Background(), job, st)
+
+	var oversized *otlp.OversizedItemError
+	if !errors.As(err, &oversized) {
+		t.Fatalf("Expected OversizedItemError, got %T: %v", err, err)
+	}
+	delivered := 0
+	for _, received := range mock.ReceivedTraces {
+		delivered += otlp.CountItems(received, structs.TelemetryTraces)
+	}
+	if delivered != 40 {
+		t.Errorf("Expected the 40 spans that fit to be delivered, got %d", delivered)
+	}
+	failed := FailedJobs(job, err)
+	if len(failed) != 1 || failed[0].Job.LineNum != 7 || otlp.CountItems(failed[0].Job.Payload, structs.TelemetryTraces) != 1 {
+		t.Errorf("Expected only the oversized span to be reported as failed, got %+v", failed)
+	}
+	if st.TracesFailed != 1 {
+		t.Errorf("Expected the oversized span to count as one failure, got %d", st.TracesFailed)
+	}
+}
+
+func TestGRPCSenderSplitsOversizedPayload(t *testing.T) {
+	mock := testutil.NewMockOTelGRPCCollector()
+	defer mock.Close()
+	cfg := config.NewConfig()
+	cfg.MaxRequestBytes = 1024
+	gs := newTestGRPCSender(t, cfg)
+	defer gs.Close()
+	st := &stats.SendStats{}
+
+	if err := gs.Send(context.Background(), mock.Endpoint(), largeTracePayload(40), structs.TelemetryTraces, st); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	traces, _, _, _ := mock.GetStats()
+	if traces < 2 || st.TracesSpans != 40 || st.TracesSuccess != traces {
+		t.Errorf("Expected several exports carrying 40 spans, got exports=%d spans=%d success=%d", traces, st.TracesSpans, st.TracesSuccess)
+	}
+}
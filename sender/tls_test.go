@@ -0,0 +1,117 @@
+package sender
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+	"github.com/laiambryant/telemetry-ingestor/testutil"
+)
+
+func writeTestFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func sendOverTLS(t *testing.T, mock *testutil.MockOTelCollector, cfg *config.Config) (bool, error) {
+	t.Helper()
+	hs := newTestHTTPSender(t, cfg)
+	defer hs.Close()
+	st := &stats.SendStats{}
+	err := hs.Send(context.Background(), mock.TracesURL(), map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st)
+	return st.TracesSuccess == 1, err
+}
+
+func TestHTTPSenderTLS(t *testing.T) {
+	mock := testutil.NewMockOTelTLSCollector(nil)
+	defer mock.Close()
+	caFile := writeTestFile(t, "ca.pem", mock.CertificatePEM())
+
+	newTest := func(cfg *config.Config, expected bool) c.CharacterizationTest[bool] {
+		return c.NewCharacterizationTest(expected, nil, func() (bool, error) {
+			ok, _ := sendOverTLS(t, mock, cfg)
+			return ok, nil
+		})
+	}
+	tests := []c.CharacterizationTest[bool]{
+		newTest(&config.Config{}, false),
+		newTest(&config.Config{TLSCAFile: caFile}, true),
+		newTest(&config.Config{InsecureSkipVerify: true}, true),
+		newTest(&config.Config{TLSCAFile: caFile, TLSServerName: "example.com"}, true),
+		newTest(&config.Config{TLSCAFile: caFile, TLSServerName: "wrong.example"}, false),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestHTTPSenderMutualTLS(t *testing.T) {
+	certPEM, keyPEM, clientCert, err := testutil.GenerateClientCertificate("telemetry-ingestor")
+	if err != nil {
+		t.Fatalf("failed to generate client certificate: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	mock := testutil.NewMockOTelTLSCollector(clientCAs)
+	defer mock.Close()
+	caFile := writeTestFile(t, "ca.pem", mock.CertificatePEM())
+	certFile := writeTestFile(t, "client.pem", certPEM)
+	keyFile := writeTestFile(t, "client-key.pem", keyPEM)
+
+	ok, err := sendOverTLS(t, mock, &config.Config{TLSCAFile: caFile})
+	var httpErr *HTTPRequestError
+	if ok || !errors.As(err, &httpErr) {
+		t.Errorf("Expected handshake without client certificate to fail, got ok=%v err=%v", ok, err)
+	}
+
+	ok, err = sendOverTLS(t, mock, &config.Config{TLSCAFile: caFile, TLSCertFile: certFile, TLSKeyFile: keyFile})
+	if !ok || err != nil {
+		t.Errorf("Expected mutual TLS send to succeed, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestNewTLSConfigErrors(t *testing.T) {
+	invalidPEM := writeTestFile(t, "invalid.pem", []byte("not a certificate"))
+	certPEM, _, _, err := testutil.GenerateClientCertificate("telemetry-ingestor")
+	if err != nil {
+		t.Fatalf("failed to generate client certificate: %v", err)
+	}
+	certFile := writeTestFile(t, "client.pem", certPEM)
+
+	configs := []*config.Config{
+		{TLSCAFile: "/nonexistent/ca.pem"},
+		{TLSCAFile: invalidPEM},
+		{TLSCertFile: certFile},
+		{TLSCertFile: certFile, TLSKeyFile: invalidPEM},
+	}
+	for _, cfg := range configs {
+		_, err := NewHTTPSender(cfg)
+		var tlsErr *TLSConfigError
+		if !errors.As(err, &tlsErr) {
+			t.Errorf("Expected TLSConfigError for %+v, got %T: %v", cfg, err, err)
+		}
+	}
+}
+
+func TestGRPCSenderTLSOptionsEnableTLS(t *testing.T) {
+	mock := testutil.NewMockOTelGRPCCollector()
+	defer mock.Close()
+	gs := newTestGRPCSender(t, &config.Config{InsecureSkipVerify: true})
+	defer gs.Close()
+	st := &stats.SendStats{}
+	if err := gs.Send(context.Background(), mock.Endpoint(), map[string]any{"resourceSpans": []any{}}, structs.TelemetryTraces, st); err == nil {
+		t.Error("Expected TLS handshake against a plaintext collector to fail")
+	}
+	if _, _, _, total := mock.GetStats(); total != 0 {
+		t.Errorf("Expected no export to reach the plaintext collector, got %d", total)
+	}
+}
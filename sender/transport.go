@@ -0,0 +1,55 @@
+package sender
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	"github.com/laiambryant/telemetry-ingestor/otlp"
+	"github.com/laiambryant/telemetry-ingestor/stats"
+	"github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// Transport delivers telemetry jobs to an OpenTelemetry collector
+type Transport interface {
+	SendJob(ctx context.Context, job structs.TelemetryJob, stats *stats.SendStats) error
+	Close() error
+}
+
+// NewTransport creates the Transport selected by cfg.Protocol, guarded by per-endpoint
+// circuit breakers unless cfg.BreakerThreshold is 0
+func NewTransport(cfg *config.Config) (Transport, error) {
+	transport, err := newProtocolTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BreakerThreshold > 0 {
+		return NewBreakerTransport(transport, cfg), nil
+	}
+	return transport, nil
+}
+
+func newProtocolTransport(cfg *config.Config) (Transport, error) {
+	if cfg.Protocol == config.PROTOCOL_GRPC {
+		grpcSender, err := NewGRPCSender(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return grpcSender, nil
+	}
+	httpSender, err := NewHTTPSender(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return httpSender, nil
+}
+
+// recordPartialSuccess records the items a collector rejected from an accepted export
+// and logs its error message against the source line of the job
+func recordPartialSuccess(job structs.TelemetryJob, partial otlp.PartialSuccess, stats *stats.SendStats) {
+	if partial.Rejected == 0 && partial.ErrorMessage == "" {
+		return
+	}
+	stats.RecordRejected(job.TelemetryType, partial.Rejected)
+	slog.Warn("Collector partially rejected telemetry", "type", job.TelemetryType, "line", job.LineNum, "rejected", partial.Rejected, "error_message", partial.ErrorMessage)
+}
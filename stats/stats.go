@@ -2,66 +2,303 @@ package stats
 
 import (
 	"log/slog"
+	"sort"
 	"sync"
 
 	s "github.com/laiambryant/telemetry-ingestor/structs"
 )
 
-// SendStats tracks success and failure counts for sending telemetry
+// SendStats tracks success and failure counts for sending telemetry. Counts recorded
+// on the stats of a destination are added to the stats it was created from as well.
 type SendStats struct {
 	mu             sync.Mutex
+	parent         *SendStats
+	destinations   map[string]*SendStats
 	TracesSuccess  int
 	TracesFailed   int
 	LogsSuccess    int
 	LogsFailed     int
 	MetricsSuccess int
 	MetricsFailed  int
+	TracesRetries  int
+	LogsRetries    int
+	MetricsRetries int
+	TracesGaveUp   int
+	LogsGaveUp     int
+	MetricsGaveUp  int
+	// Items rejected through OTLP partial success: spans, log records and data points
+	TracesRejected  int
+	LogsRejected    int
+	MetricsRejected int
+	// Items dispatched in requests: spans, log records and data points
+	TracesSpans       int
+	LogsRecords       int
+	MetricsDataPoints int
+	// Request body bytes dispatched, after encoding and before compression
+	TracesBytes  int64
+	LogsBytes    int64
+	MetricsBytes int64
+	// Jobs failed fast because the circuit breaker of their endpoint was open
+	TracesShortCircuited  int
+	LogsShortCircuited    int
+	MetricsShortCircuited int
+	// Circuit breaker state changes across all endpoints
+	BreakerOpened     int
+	BreakerHalfOpened int
+	BreakerClosed     int
 }
 
-// RecordSuccess increments the success counter for the given telemetry type
-func (ss *SendStats) RecordSuccess(telemetryType s.TelemetryType) {
+// Destination returns the stats of a single destination, creating them on first use
+func (ss *SendStats) Destination(endpoint string) *SendStats {
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
-	switch telemetryType {
-	case s.TelemetryTraces:
-		ss.TracesSuccess++
-	case s.TelemetryLogs:
-		ss.LogsSuccess++
-	case s.TelemetryMetrics:
-		ss.MetricsSuccess++
+	if ss.destinations == nil {
+		ss.destinations = make(map[string]*SendStats)
+	}
+	destination, ok := ss.destinations[endpoint]
+	if !ok {
+		destination = &SendStats{parent: ss}
+		ss.destinations[endpoint] = destination
+	}
+	return destination
+}
+
+// update applies a change to these stats and to every stats they were created from
+func (ss *SendStats) update(change func(ss *SendStats)) {
+	for current := ss; current != nil; current = current.parent {
+		current.mu.Lock()
+		change(current)
+		current.mu.Unlock()
 	}
 }
 
+// RecordSuccess increments the success counter for the given telemetry type
+func (ss *SendStats) RecordSuccess(telemetryType s.TelemetryType) {
+	ss.update(func(ss *SendStats) {
+		switch telemetryType {
+		case s.TelemetryTraces:
+			ss.TracesSuccess++
+		case s.TelemetryLogs:
+			ss.LogsSuccess++
+		case s.TelemetryMetrics:
+			ss.MetricsSuccess++
+		}
+	})
+}
+
 // RecordFailure increments the failure counter for the given telemetry type
 func (ss *SendStats) RecordFailure(telemetryType s.TelemetryType) {
-	ss.mu.Lock()
-	defer ss.mu.Unlock()
-	switch telemetryType {
-	case s.TelemetryTraces:
-		ss.TracesFailed++
-	case s.TelemetryLogs:
-		ss.LogsFailed++
-	case s.TelemetryMetrics:
-		ss.MetricsFailed++
-	}
+	ss.update(func(ss *SendStats) {
+		switch telemetryType {
+		case s.TelemetryTraces:
+			ss.TracesFailed++
+		case s.TelemetryLogs:
+			ss.LogsFailed++
+		case s.TelemetryMetrics:
+			ss.MetricsFailed++
+		}
+	})
+}
+
+// RecordRetry increments the retry counter for the given telemetry type
+func (ss *SendStats) RecordRetry(telemetryType s.TelemetryType) {
+	ss.update(func(ss *SendStats) {
+		switch telemetryType {
+		case s.TelemetryTraces:
+			ss.TracesRetries++
+		case s.TelemetryLogs:
+			ss.LogsRetries++
+		case s.TelemetryMetrics:
+			ss.MetricsRetries++
+		}
+	})
+}
+
+// RecordGiveUp increments the counter of sends abandoned after exhausting their retries
+func (ss *SendStats) RecordGiveUp(telemetryType s.TelemetryType) {
+	ss.update(func(ss *SendStats) {
+		switch telemetryType {
+		case s.TelemetryTraces:
+			ss.TracesGaveUp++
+		case s.TelemetryLogs:
+			ss.LogsGaveUp++
+		case s.TelemetryMetrics:
+			ss.MetricsGaveUp++
+		}
+	})
+}
+
+// RecordRejected adds the items a collector rejected from an accepted export
+func (ss *SendStats) RecordRejected(telemetryType s.TelemetryType, count int64) {
+	ss.update(func(ss *SendStats) {
+		switch telemetryType {
+		case s.TelemetryTraces:
+			ss.TracesRejected += int(count)
+		case s.TelemetryLogs:
+			ss.LogsRejected += int(count)
+		case s.TelemetryMetrics:
+			ss.MetricsRejected += int(count)
+		}
+	})
+}
+
+// RecordDispatched adds the items and body bytes of a request dispatched for the given telemetry type
+func (ss *SendStats) RecordDispatched(telemetryType s.TelemetryType, items int, bytes int64) {
+	ss.update(func(ss *SendStats) {
+		switch telemetryType {
+		case s.TelemetryTraces:
+			ss.TracesSpans += items
+			ss.TracesBytes += bytes
+		case s.TelemetryLogs:
+			ss.LogsRecords += items
+			ss.LogsBytes += bytes
+		case s.TelemetryMetrics:
+			ss.MetricsDataPoints += items
+			ss.MetricsBytes += bytes
+		}
+	})
+}
+
+// RecordShortCircuit increments the counter of jobs failed fast by an open circuit breaker
+func (ss *SendStats) RecordShortCircuit(telemetryType s.TelemetryType) {
+	ss.update(func(ss *SendStats) {
+		switch telemetryType {
+		case s.TelemetryTraces:
+			ss.TracesShortCircuited++
+		case s.TelemetryLogs:
+			ss.LogsShortCircuited++
+		case s.TelemetryMetrics:
+			ss.MetricsShortCircuited++
+		}
+	})
+}
+
+// RecordBreakerOpen counts a circuit breaker moving to open
+func (ss *SendStats) RecordBreakerOpen() {
+	ss.update(func(ss *SendStats) {
+		ss.BreakerOpened++
+	})
+}
+
+// RecordBreakerHalfOpen counts a circuit breaker moving to half-open
+func (ss *SendStats) RecordBreakerHalfOpen() {
+	ss.update(func(ss *SendStats) {
+		ss.BreakerHalfOpened++
+	})
+}
+
+// RecordBreakerClose counts a circuit breaker moving back to closed
+func (ss *SendStats) RecordBreakerClose() {
+	ss.update(func(ss *SendStats) {
+		ss.BreakerClosed++
+	})
+}
+
+// Totals returns the successful and failed requests and the bytes dispatched across every telemetry type
+func (s *SendStats) Totals() (success, failed int, bytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.TracesSuccess + s.LogsSuccess + s.MetricsSuccess, s.TracesFailed + s.LogsFailed + s.MetricsFailed, s.TracesBytes + s.LogsBytes + s.MetricsBytes
 }
 
-// PrintSummary prints a summary of the send statistics
+// PrintSummary prints a summary of the send statistics. When a signal was sent to several
+// destinations, the counts of every destination are printed as well.
 func (s *SendStats) PrintSummary() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	slog.Info("=== Telemetry Send Summary ===")
+	s.printSignals()
+	if s.BreakerOpened > 0 || s.BreakerHalfOpened > 0 || s.BreakerClosed > 0 {
+		slog.Info("Circuit breakers", "opened", s.BreakerOpened, "half_opened", s.BreakerHalfOpened, "closed", s.BreakerClosed)
+	}
+	if s.fannedOut() {
+		endpoints := make([]string, 0, len(s.destinations))
+		for endpoint := range s.destinations {
+			endpoints = append(endpoints, endpoint)
+		}
+		sort.Strings(endpoints)
+		for _, endpoint := range endpoints {
+			destination := s.destinations[endpoint]
+			destination.mu.Lock()
+			destination.printSignals("destination", endpoint)
+			destination.mu.Unlock()
+		}
+	}
+	totalSuccess := s.TracesSuccess + s.LogsSuccess + s.MetricsSuccess
+	totalFailed := s.TracesFailed + s.LogsFailed + s.MetricsFailed
+	totalRetries := s.TracesRetries + s.LogsRetries + s.MetricsRetries
+	totalGaveUp := s.TracesGaveUp + s.LogsGaveUp + s.MetricsGaveUp
+	totalBytes := s.TracesBytes + s.LogsBytes + s.MetricsBytes
+	slog.Info("Total", "success", totalSuccess, "failed", totalFailed, "retries", totalRetries, "gave_up", totalGaveUp, "bytes", totalBytes)
+}
+
+// PrintDryRunReport prints what a dry run would have sent: the requests, items and bytes
+// per signal, followed by the same counts for every endpoint that would have been hit
+func (s *SendStats) PrintDryRunReport() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slog.Info("=== Dry Run Report (nothing was sent) ===")
+	s.printRequests()
+	endpoints := make([]string, 0, len(s.destinations))
+	for endpoint := range s.destinations {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	for _, endpoint := range endpoints {
+		destination := s.destinations[endpoint]
+		destination.mu.Lock()
+		destination.printRequests("endpoint", endpoint)
+		destination.mu.Unlock()
+	}
+	totalRequests := s.TracesSuccess + s.LogsSuccess + s.MetricsSuccess
+	totalFailed := s.TracesFailed + s.LogsFailed + s.MetricsFailed
+	totalBytes := s.TracesBytes + s.LogsBytes + s.MetricsBytes
+	slog.Info("Total", "requests", totalRequests, "failed", totalFailed, "bytes", totalBytes, "endpoints", len(endpoints))
+}
+
+// printRequests prints the requests, items and bytes of every telemetry type, prefixed with args
+func (s *SendStats) printRequests(args ...any) {
 	if s.TracesSuccess > 0 || s.TracesFailed > 0 {
-		slog.Info("Traces", "success", s.TracesSuccess, "failed", s.TracesFailed)
+		slog.Info("Traces", append(args, "requests", s.TracesSuccess, "failed", s.TracesFailed, "spans", s.TracesSpans, "bytes", s.TracesBytes)...)
 	}
 	if s.LogsSuccess > 0 || s.LogsFailed > 0 {
-		slog.Info("Logs", "success", s.LogsSuccess, "failed", s.LogsFailed)
+		slog.Info("Logs", append(args, "requests", s.LogsSuccess, "failed", s.LogsFailed, "log_records", s.LogsRecords, "bytes", s.LogsBytes)...)
 	}
 	if s.MetricsSuccess > 0 || s.MetricsFailed > 0 {
-		slog.Info("Metrics", "success", s.MetricsSuccess, "failed", s.MetricsFailed)
+		slog.Info("Metrics", append(args, "requests", s.MetricsSuccess, "failed", s.MetricsFailed, "data_points", s.MetricsDataPoints, "bytes", s.MetricsBytes)...)
 	}
-	totalSuccess := s.TracesSuccess + s.LogsSuccess + s.MetricsSuccess
-	totalFailed := s.TracesFailed + s.LogsFailed + s.MetricsFailed
-	slog.Info("Total", "success", totalSuccess, "failed", totalFailed)
+}
+
+// printSignals prints one line per telemetry type that was sent, prefixed with args
+func (s *SendStats) printSignals(args ...any) {
+	if s.TracesSuccess > 0 || s.TracesFailed > 0 {
+		slog.Info("Traces", append(args, "success", s.TracesSuccess, "failed", s.TracesFailed, "spans", s.TracesSpans, "bytes", s.TracesBytes, "retries", s.TracesRetries, "gave_up", s.TracesGaveUp, "rejected_spans", s.TracesRejected, "short_circuited", s.TracesShortCircuited)...)
+	}
+	if s.LogsSuccess > 0 || s.LogsFailed > 0 {
+		slog.Info("Logs", append(args, "success", s.LogsSuccess, "failed", s.LogsFailed, "log_records", s.LogsRecords, "bytes", s.LogsBytes, "retries", s.LogsRetries, "gave_up", s.LogsGaveUp, "rejected_log_records", s.LogsRejected, "short_circuited", s.LogsShortCircuited)...)
+	}
+	if s.MetricsSuccess > 0 || s.MetricsFailed > 0 {
+		slog.Info("Metrics", append(args, "success", s.MetricsSuccess, "failed", s.MetricsFailed, "data_points", s.MetricsDataPoints, "bytes", s.MetricsBytes, "retries", s.MetricsRetries, "gave_up", s.MetricsGaveUp, "rejected_data_points", s.MetricsRejected, "short_circuited", s.MetricsShortCircuited)...)
+	}
+}
+
+// fannedOut reports whether any telemetry type was sent to more than one destination
+func (s *SendStats) fannedOut() bool {
+	var traces, logs, metrics int
+	for _, destination := range s.destinations {
+		destination.mu.Lock()
+		if destination.TracesSuccess > 0 || destination.TracesFailed > 0 {
+			traces++
+		}
+		if destination.LogsSuccess > 0 || destination.LogsFailed > 0 {
+			logs++
+		}
+		if destination.MetricsSuccess > 0 || destination.MetricsFailed > 0 {
+			metrics++
+		}
+		destination.mu.Unlock()
+	}
+	return traces > 1 || logs > 1 || metrics > 1
 }
@@ -276,6 +276,32 @@ func TestPrintSummaryWithAllStats(t *testing.T) {
 	c.VerifyCharacterizationTestsAndResults(t, tests, false)
 }
 
+func TestRecordRetryAndGiveUp(t *testing.T) {
+	ss := &SendStats{}
+	ss.RecordRetry(s.TelemetryTraces)
+	ss.RecordRetry(s.TelemetryTraces)
+	ss.RecordRetry(s.TelemetryLogs)
+	ss.RecordRetry(s.TelemetryMetrics)
+	ss.RecordGiveUp(s.TelemetryLogs)
+	ss.RecordGiveUp(s.TelemetryMetrics)
+	if ss.TracesRetries != 2 || ss.LogsRetries != 1 || ss.MetricsRetries != 1 {
+		t.Errorf("Unexpected retries: traces=%d logs=%d metrics=%d", ss.TracesRetries, ss.LogsRetries, ss.MetricsRetries)
+	}
+	if ss.TracesGaveUp != 0 || ss.LogsGaveUp != 1 || ss.MetricsGaveUp != 1 {
+		t.Errorf("Unexpected give-ups: traces=%d logs=%d metrics=%d", ss.TracesGaveUp, ss.LogsGaveUp, ss.MetricsGaveUp)
+	}
+
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{})))
+	ss.RecordFailure(s.TelemetryLogs)
+	ss.PrintSummary()
+	for _, expected := range []string{"retries=1", "gave_up=1", "retries=4", "gave_up=2"} {
+		if !bytes.Contains(buf.Bytes(), []byte(expected)) {
+			t.Errorf("PrintSummary() output missing %s", expected)
+		}
+	}
+}
+
 func TestPrintSummaryWithOnlyTraces(t *testing.T) {
 	var buf bytes.Buffer
 	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{}))
@@ -395,3 +421,132 @@ func TestSendStatsInitialization(t *testing.T) {
 		t.Errorf("MetricsFailed = %d, want 0", ss.MetricsFailed)
 	}
 }
+
+func TestRecordRejected(t *testing.T) {
+	ss := &SendStats{}
+	ss.RecordRejected(s.TelemetryTraces, 4)
+	ss.RecordRejected(s.TelemetryTraces, 1)
+	ss.RecordRejected(s.TelemetryLogs, 2)
+	ss.RecordRejected(s.TelemetryMetrics, 9)
+	if ss.TracesRejected != 5 || ss.LogsRejected != 2 || ss.MetricsRejected != 9 {
+		t.Errorf("Unexpected rejected counts: traces=%d logs=%d metrics=%d", ss.TracesRejected, ss.LogsRejected, ss.MetricsRejected)
+	}
+
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{})))
+	ss.RecordSuccess(s.TelemetryTraces)
+	ss.RecordSuccess(s.TelemetryLogs)
+	ss.RecordSuccess(s.TelemetryMetrics)
+	ss.PrintSummary()
+	for _, expected := range []string{"rejected_spans=5", "rejected_log_records=2", "rejected_data_points=9"} {
+		if !bytes.Contains(buf.Bytes(), []byte(expected)) {
+			t.Errorf("PrintSummary() output missing %s", expected)
+		}
+	}
+}
+
+func TestRecordDispatched(t *testing.T) {
+	ss := &SendStats{}
+	ss.RecordDispatched(s.TelemetryTraces, 10, 1024)
+	ss.RecordDispatched(s.TelemetryTraces, 5, 512)
+	ss.RecordDispatched(s.TelemetryLogs, 3, 100)
+	ss.RecordDispatched(s.TelemetryMetrics, 42, 2000)
+	if ss.TracesSpans != 15 || ss.LogsRecords != 3 || ss.MetricsDataPoints != 42 {
+		t.Errorf("Unexpected item counts: spans=%d log_records=%d data_points=%d", ss.TracesSpans, ss.LogsRecords, ss.MetricsDataPoints)
+	}
+	if ss.TracesBytes != 1536 || ss.LogsBytes != 100 || ss.MetricsBytes != 2000 {
+		t.Errorf("Unexpected byte counts: traces=%d logs=%d metrics=%d", ss.TracesBytes, ss.LogsBytes, ss.MetricsBytes)
+	}
+
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{})))
+	ss.RecordSuccess(s.TelemetryTraces)
+	ss.RecordSuccess(s.TelemetryLogs)
+	ss.RecordFailure(s.TelemetryMetrics)
+	ss.PrintSummary()
+	for _, expected := range []string{"spans=15", "bytes=1536", "log_records=3", "data_points=42", "bytes=3636"} {
+		if !bytes.Contains(buf.Bytes(), []byte(expected)) {
+			t.Errorf("PrintSummary() output missing %s", expected)
+		}
+	}
+}
+
+func TestRecordCircuitBreaker(t *testing.T) {
+	ss := &SendStats{}
+	ss.RecordShortCircuit(s.TelemetryLogs)
+	ss.RecordShortCircuit(s.TelemetryLogs)
+	ss.RecordShortCircuit(s.TelemetryMetrics)
+	ss.RecordBreakerOpen()
+	ss.RecordBreakerOpen()
+	ss.RecordBreakerHalfOpen()
+	ss.RecordBreakerClose()
+	if ss.TracesShortCircuited != 0 || ss.LogsShortCircuited != 2 || ss.MetricsShortCircuited != 1 {
+		t.Errorf("Unexpected short-circuit counts: traces=%d logs=%d metrics=%d", ss.TracesShortCircuited, ss.LogsShortCircuited, ss.MetricsShortCircuited)
+	}
+	if ss.BreakerOpened != 2 || ss.BreakerHalfOpened != 1 || ss.BreakerClosed != 1 {
+		t.Errorf("Unexpected transition counts: opened=%d half_opened=%d closed=%d", ss.BreakerOpened, ss.BreakerHalfOpened, ss.BreakerClosed)
+	}
+
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{})))
+	ss.RecordFailure(s.TelemetryLogs)
+	ss.PrintSummary()
+	for _, expected := range []string{"short_circuited=2", "opened=2", "half_opened=1", "closed=1"} {
+		if !bytes.Contains(buf.Bytes(), []byte(expected)) {
+			t.Errorf("PrintSummary() output missing %s", expected)
+		}
+	}
+}
+
+func TestDestinationStats(t *testing.T) {
+	ss := &SendStats{}
+	first := ss.Destination("http://a/v1/traces")
+	second := ss.Destination("http://b/v1/traces")
+	if ss.Destination("http://a/v1/traces") != first {
+		t.Fatal("Expected Destination to return the same stats for the same endpoint")
+	}
+	first.RecordSuccess(s.TelemetryTraces)
+	first.RecordDispatched(s.TelemetryTraces, 4, 100)
+	second.RecordFailure(s.TelemetryTraces)
+	second.RecordRetry(s.TelemetryTraces)
+	if first.TracesSuccess != 1 || first.TracesFailed != 0 || second.TracesSuccess != 0 || second.TracesFailed != 1 {
+		t.Errorf("Expected independent destination counts, got a=%d/%d b=%d/%d", first.TracesSuccess, first.TracesFailed, second.TracesSuccess, second.TracesFailed)
+	}
+	if ss.TracesSuccess != 1 || ss.TracesFailed != 1 || ss.TracesRetries != 1 || ss.TracesSpans != 4 {
+		t.Errorf("Expected destination counts to add up in the totals, got success=%d failed=%d retries=%d spans=%d", ss.TracesSuccess, ss.TracesFailed, ss.TracesRetries, ss.TracesSpans)
+	}
+
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{})))
+	ss.PrintSummary()
+	for _, expected := range []string{"destination=http://a/v1/traces", "destination=http://b/v1/traces"} {
+		if !bytes.Contains(buf.Bytes(), []byte(expected)) {
+			t.Errorf("PrintSummary() output missing %s", expected)
+		}
+	}
+
+	buf.Reset()
+	single := &SendStats{}
+	single.Destination("http://a/v1/traces").RecordSuccess(s.TelemetryTraces)
+	single.Destination("http://a/v1/logs").RecordSuccess(s.TelemetryLogs)
+	single.PrintSummary()
+	if bytes.Contains(buf.Bytes(), []byte("destination=")) {
+		t.Error("Expected no per-destination lines when every signal has a single destination")
+	}
+}
+
+func TestPrintDryRunReport(t *testing.T) {
+	ss := &SendStats{}
+	ss.Destination("http://a/v1/traces").RecordSuccess(s.TelemetryTraces)
+	ss.Destination("http://a/v1/traces").RecordDispatched(s.TelemetryTraces, 3, 120)
+	ss.Destination("http://a/v1/logs").RecordSuccess(s.TelemetryLogs)
+
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{})))
+	ss.PrintDryRunReport()
+	for _, expected := range []string{"nothing was sent", "requests=1", "spans=3", "bytes=120", "endpoint=http://a/v1/traces", "endpoint=http://a/v1/logs", "requests=2", "endpoints=2"} {
+		if !bytes.Contains(buf.Bytes(), []byte(expected)) {
+			t.Errorf("PrintDryRunReport() output missing %s", expected)
+		}
+	}
+}
@@ -31,4 +31,15 @@ type TelemetryJob struct {
 	Payload       any
 	TelemetryType TelemetryType
 	LineNum       int
+	// Lines lists every source line merged into a batched payload, in order.
+	// It is empty for jobs built from a single line, which only use LineNum.
+	Lines []int
+}
+
+// SourceLines returns the source lines the job payload was built from
+func (j TelemetryJob) SourceLines() []int {
+	if len(j.Lines) > 0 {
+		return j.Lines
+	}
+	return []int{j.LineNum}
 }
@@ -1,6 +1,7 @@
 package structs
 
 import (
+	"fmt"
 	"testing"
 
 	c "github.com/laiambryant/gotestutils/ctesting"
@@ -38,3 +39,16 @@ func TestTelemetryTypeString(t *testing.T) {
 	tests := []c.CharacterizationTest[string]{test1, test2, test3, test4}
 	c.VerifyCharacterizationTestsAndResults(t, tests, false)
 }
+
+func TestTelemetryJobSourceLines(t *testing.T) {
+	newTest := func(job TelemetryJob, expected string) c.CharacterizationTest[string] {
+		return c.NewCharacterizationTest(expected, nil, func() (string, error) {
+			return fmt.Sprint(job.SourceLines()), nil
+		})
+	}
+	tests := []c.CharacterizationTest[string]{
+		newTest(TelemetryJob{LineNum: 7}, "[7]"),
+		newTest(TelemetryJob{LineNum: 3, Lines: []int{3, 5, 8}}, "[3 5 8]"),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
@@ -1,10 +1,21 @@
 package testutil
 
 import (
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/laiambryant/telemetry-ingestor/otlp"
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+	"google.golang.org/protobuf/proto"
 )
 
 type MockOTelCollector struct {
@@ -12,52 +23,100 @@ type MockOTelCollector struct {
 	ReceivedTraces  []map[string]any
 	ReceivedLogs    []map[string]any
 	ReceivedMetrics []map[string]any
+	ReceivedHeaders []http.Header
 	mu              sync.Mutex
 	RequestCount    int
 	ShouldFail      bool
+	responses       []MockResponse
+}
+
+// MockResponse scripts the answer to a single request. A zero StatusCode with
+// CloseConnection set drops the connection without responding.
+type MockResponse struct {
+	StatusCode      int
+	RetryAfter      string
+	ContentType     string
+	Body            string
+	CloseConnection bool
 }
 
 func NewMockOTelCollector() *MockOTelCollector {
+	mock, mux := newMockOTelCollector()
+	mock.Server = httptest.NewServer(mux)
+	return mock
+}
+
+// NewMockOTelTLSCollector starts the mock collector behind TLS using the
+// httptest certificate. When clientCAs is not nil, clients must present a
+// certificate signed by one of them.
+func NewMockOTelTLSCollector(clientCAs *x509.CertPool) *MockOTelCollector {
+	mock, mux := newMockOTelCollector()
+	mock.Server = httptest.NewUnstartedServer(mux)
+	if clientCAs != nil {
+		mock.Server.TLS = &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+		}
+	}
+	mock.Server.StartTLS()
+	return mock
+}
+
+func newMockOTelCollector() (*MockOTelCollector, *http.ServeMux) {
 	mock := &MockOTelCollector{
 		ReceivedTraces:  make([]map[string]any, 0),
 		ReceivedLogs:    make([]map[string]any, 0),
 		ReceivedMetrics: make([]map[string]any, 0),
+		ReceivedHeaders: make([]http.Header, 0),
 	}
 
 	mux := http.NewServeMux()
 	registerHandlers(mux, mock)
-
-	mock.Server = httptest.NewServer(mux)
-	return mock
+	return mock, mux
 }
 
 func registerHandlers(mux *http.ServeMux, mock *MockOTelCollector) {
-	mux.HandleFunc("/v1/traces", makeHandler(mock, func(m *MockOTelCollector, data map[string]any) {
+	mux.HandleFunc("/v1/traces", makeHandler(mock, s.TelemetryTraces, func(m *MockOTelCollector, data map[string]any) {
 		m.ReceivedTraces = append(m.ReceivedTraces, data)
 	}))
 
-	mux.HandleFunc("/v1/logs", makeHandler(mock, func(m *MockOTelCollector, data map[string]any) {
+	mux.HandleFunc("/v1/logs", makeHandler(mock, s.TelemetryLogs, func(m *MockOTelCollector, data map[string]any) {
 		m.ReceivedLogs = append(m.ReceivedLogs, data)
 	}))
 
-	mux.HandleFunc("/v1/metrics", makeHandler(mock, func(m *MockOTelCollector, data map[string]any) {
+	mux.HandleFunc("/v1/metrics", makeHandler(mock, s.TelemetryMetrics, func(m *MockOTelCollector, data map[string]any) {
 		m.ReceivedMetrics = append(m.ReceivedMetrics, data)
 	}))
 }
 
-func makeHandler(mock *MockOTelCollector, appendFunc func(*MockOTelCollector, map[string]any)) http.HandlerFunc {
+func makeHandler(mock *MockOTelCollector, telemetryType s.TelemetryType, appendFunc func(*MockOTelCollector, map[string]any)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		mock.mu.Lock()
 		defer mock.mu.Unlock()
 		mock.RequestCount++
+		mock.ReceivedHeaders = append(mock.ReceivedHeaders, r.Header.Clone())
+
+		if len(mock.responses) > 0 {
+			response := mock.responses[0]
+			mock.responses = mock.responses[1:]
+			writeScriptedResponse(w, response)
+			return
+		}
 
 		if mock.ShouldFail {
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		var data map[string]any
-		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		body, err := decompressBody(r.Body, r.Header.Get("Content-Encoding"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer body.Close()
+
+		data, err := decodeBody(body, r.Header.Get("Content-Type"), telemetryType)
+		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
@@ -67,10 +126,77 @@ func makeHandler(mock *MockOTelCollector, appendFunc func(*MockOTelCollector, ma
 	}
 }
 
+func writeScriptedResponse(w http.ResponseWriter, response MockResponse) {
+	if response.CloseConnection {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+	if response.RetryAfter != "" {
+		w.Header().Set("Retry-After", response.RetryAfter)
+	}
+	if response.ContentType != "" {
+		w.Header().Set("Content-Type", response.ContentType)
+	}
+	w.WriteHeader(response.StatusCode)
+	io.WriteString(w, response.Body)
+}
+
+// decompressBody transparently undoes the Content-Encoding of a request body
+func decompressBody(body io.Reader, contentEncoding string) (io.ReadCloser, error) {
+	switch contentEncoding {
+	case "", "identity":
+		return io.NopCloser(body), nil
+	case "gzip":
+		return gzip.NewReader(body)
+	case "zstd":
+		decoder, err := zstd.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", contentEncoding)
+	}
+}
+
+// decodeBody decodes an OTLP/JSON or OTLP/protobuf request body into its JSON map form
+func decodeBody(body io.Reader, contentType string, telemetryType s.TelemetryType) (map[string]any, error) {
+	if contentType != "application/x-protobuf" {
+		var data map[string]any
+		if err := json.NewDecoder(body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := otlp.NewExportRequest(telemetryType)
+	if err != nil {
+		return nil, err
+	}
+	if err := proto.Unmarshal(raw, msg); err != nil {
+		return nil, err
+	}
+	return otlp.FromProto(msg)
+}
+
 func (m *MockOTelCollector) Close() {
 	m.Server.Close()
 }
 
+// CertificatePEM returns the PEM encoded server certificate of a TLS collector,
+// suitable for use as a CA bundle
+func (m *MockOTelCollector) CertificatePEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: m.Server.Certificate().Raw})
+}
+
 func (m *MockOTelCollector) TracesURL() string {
 	return m.Server.URL + "/v1/traces"
 }
@@ -83,12 +209,22 @@ func (m *MockOTelCollector) MetricsURL() string {
 	return m.Server.URL + "/v1/metrics"
 }
 
+// QueueResponses scripts the responses for the next requests, in order, before
+// the collector falls back to its default behaviour
+func (m *MockOTelCollector) QueueResponses(responses ...MockResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses = append(m.responses, responses...)
+}
+
 func (m *MockOTelCollector) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.responses = nil
 	m.ReceivedTraces = make([]map[string]any, 0)
 	m.ReceivedLogs = make([]map[string]any, 0)
 	m.ReceivedMetrics = make([]map[string]any, 0)
+	m.ReceivedHeaders = make([]http.Header, 0)
 	m.RequestCount = 0
 	m.ShouldFail = false
 }
@@ -2,6 +2,7 @@ package testutil
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -65,6 +66,87 @@ func TestMockOTelCollectorFailure(t *testing.T) {
 	}
 }
 
+func TestMockOTelCollectorQueuedResponses(t *testing.T) {
+	mock := NewMockOTelCollector()
+	defer mock.Close()
+	mock.QueueResponses(MockResponse{StatusCode: http.StatusTooManyRequests, RetryAfter: "7"})
+
+	resp := sendRequestWithResponse(t, mock.TracesURL(), tracesData)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") != "7" {
+		t.Errorf("Expected Retry-After 7, got %q", resp.Header.Get("Retry-After"))
+	}
+	sendRequest(t, mock.TracesURL(), tracesData)
+	if traces, _, _, total := mock.GetStats(); traces != 1 || total != 2 {
+		t.Errorf("Expected queued response to be consumed once, got traces=%d total=%d", traces, total)
+	}
+
+	mock.QueueResponses(MockResponse{CloseConnection: true})
+	body, _ := json.Marshal(tracesData)
+	if _, err := http.Post(mock.TracesURL(), "application/json", bytes.NewBuffer(body)); err == nil {
+		t.Error("Expected closed connection to surface as a transport error")
+	}
+}
+
+func TestMockOTelCollectorDecompressesGzip(t *testing.T) {
+	mock := NewMockOTelCollector()
+	defer mock.Close()
+
+	body, _ := json.Marshal(logsData)
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(body)
+	gz.Close()
+	req, _ := http.NewRequest(http.MethodPost, mock.LogsURL(), &buf)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if _, logs, _, _ := mock.GetStats(); logs != 1 {
+		t.Errorf("Expected 1 decoded log payload, got %d", logs)
+	}
+
+	req, _ = http.NewRequest(http.MethodPost, mock.LogsURL(), bytes.NewBufferString("{}"))
+	req.Header.Set("Content-Encoding", "br")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unsupported encoding, got %d", resp.StatusCode)
+	}
+}
+
+func TestMockOTelTLSCollector(t *testing.T) {
+	mock := NewMockOTelTLSCollector(nil)
+	defer mock.Close()
+
+	body, _ := json.Marshal(metricsData)
+	resp, err := mock.Server.Client().Post(mock.MetricsURL(), "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	resp.Body.Close()
+	if _, _, metrics, _ := mock.GetStats(); metrics != 1 {
+		t.Errorf("Expected 1 metric over TLS, got %d", metrics)
+	}
+	if !bytes.HasPrefix(mock.CertificatePEM(), []byte("-----BEGIN CERTIFICATE-----")) {
+		t.Error("Expected CertificatePEM to return a PEM certificate")
+	}
+	if _, err := http.Post(mock.MetricsURL(), "application/json", bytes.NewBuffer(body)); err == nil {
+		t.Error("Expected default client to reject the self-signed certificate")
+	}
+}
+
 func TestMockOTelCollectorBadJSON(t *testing.T) {
 	mock := NewMockOTelCollector()
 	defer mock.Close()
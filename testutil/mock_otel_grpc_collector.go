@@ -0,0 +1,177 @@
+package testutil
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/laiambryant/telemetry-ingestor/otlp"
+	collogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type MockOTelGRPCCollector struct {
+	Server           *grpc.Server
+	ReceivedTraces   []map[string]any
+	ReceivedLogs     []map[string]any
+	ReceivedMetrics  []map[string]any
+	ReceivedMetadata []metadata.MD
+	mu               sync.Mutex
+	listener         net.Listener
+	RequestCount     int
+	ShouldFail       bool
+	errors           []error
+	partialSuccesses []otlp.PartialSuccess
+}
+
+func NewMockOTelGRPCCollector() *MockOTelGRPCCollector {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("testutil: failed to listen on a port: " + err.Error())
+	}
+
+	mock := &MockOTelGRPCCollector{
+		ReceivedTraces:   make([]map[string]any, 0),
+		ReceivedLogs:     make([]map[string]any, 0),
+		ReceivedMetrics:  make([]map[string]any, 0),
+		ReceivedMetadata: make([]metadata.MD, 0),
+		listener:         listener,
+	}
+
+	mock.Server = grpc.NewServer()
+	coltrace.RegisterTraceServiceServer(mock.Server, &mockTraceService{mock: mock})
+	collogs.RegisterLogsServiceServer(mock.Server, &mockLogsService{mock: mock})
+	colmetrics.RegisterMetricsServiceServer(mock.Server, &mockMetricsService{mock: mock})
+	go mock.Server.Serve(listener)
+	return mock
+}
+
+type mockTraceService struct {
+	coltrace.UnimplementedTraceServiceServer
+	mock *MockOTelGRPCCollector
+}
+
+func (s *mockTraceService) Export(ctx context.Context, req *coltrace.ExportTraceServiceRequest) (*coltrace.ExportTraceServiceResponse, error) {
+	partial, err := s.mock.record(ctx, req, func(m *MockOTelGRPCCollector, data map[string]any) {
+		m.ReceivedTraces = append(m.ReceivedTraces, data)
+	})
+	resp := &coltrace.ExportTraceServiceResponse{}
+	if partial != nil {
+		resp.PartialSuccess = &coltrace.ExportTracePartialSuccess{RejectedSpans: partial.Rejected, ErrorMessage: partial.ErrorMessage}
+	}
+	return resp, err
+}
+
+type mockLogsService struct {
+	collogs.UnimplementedLogsServiceServer
+	mock *MockOTelGRPCCollector
+}
+
+func (s *mockLogsService) Export(ctx context.Context, req *collogs.ExportLogsServiceRequest) (*collogs.ExportLogsServiceResponse, error) {
+	partial, err := s.mock.record(ctx, req, func(m *MockOTelGRPCCollector, data map[string]any) {
+		m.ReceivedLogs = append(m.ReceivedLogs, data)
+	})
+	resp := &collogs.ExportLogsServiceResponse{}
+	if partial != nil {
+		resp.PartialSuccess = &collogs.ExportLogsPartialSuccess{RejectedLogRecords: partial.Rejected, ErrorMessage: partial.ErrorMessage}
+	}
+	return resp, err
+}
+
+type mockMetricsService struct {
+	colmetrics.UnimplementedMetricsServiceServer
+	mock *MockOTelGRPCCollector
+}
+
+func (s *mockMetricsService) Export(ctx context.Context, req *colmetrics.ExportMetricsServiceRequest) (*colmetrics.ExportMetricsServiceResponse, error) {
+	partial, err := s.mock.record(ctx, req, func(m *MockOTelGRPCCollector, data map[string]any) {
+		m.ReceivedMetrics = append(m.ReceivedMetrics, data)
+	})
+	resp := &colmetrics.ExportMetricsServiceResponse{}
+	if partial != nil {
+		resp.PartialSuccess = &colmetrics.ExportMetricsPartialSuccess{RejectedDataPoints: partial.Rejected, ErrorMessage: partial.ErrorMessage}
+	}
+	return resp, err
+}
+
+// record stores an export request and returns the partial success queued for it, if any
+func (m *MockOTelGRPCCollector) record(ctx context.Context, req proto.Message, appendFunc func(*MockOTelGRPCCollector, map[string]any)) (*otlp.PartialSuccess, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.RequestCount++
+	md, _ := metadata.FromIncomingContext(ctx)
+	m.ReceivedMetadata = append(m.ReceivedMetadata, md)
+
+	if len(m.errors) > 0 {
+		err := m.errors[0]
+		m.errors = m.errors[1:]
+		return nil, err
+	}
+
+	if m.ShouldFail {
+		return nil, status.Error(codes.Internal, "mock collector failure")
+	}
+
+	data, err := otlp.FromProto(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	appendFunc(m, data)
+
+	if len(m.partialSuccesses) > 0 {
+		partial := m.partialSuccesses[0]
+		m.partialSuccesses = m.partialSuccesses[1:]
+		return &partial, nil
+	}
+	return nil, nil
+}
+
+func (m *MockOTelGRPCCollector) Close() {
+	m.Server.Stop()
+}
+
+// Endpoint returns the host:port the collector is listening on
+func (m *MockOTelGRPCCollector) Endpoint() string {
+	return m.listener.Addr().String()
+}
+
+// QueueErrors scripts the status errors returned by the next exports, in order,
+// before the collector falls back to its default behaviour
+func (m *MockOTelGRPCCollector) QueueErrors(errs ...error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors = append(m.errors, errs...)
+}
+
+// QueuePartialSuccesses scripts partial success responses for the next accepted exports, in order
+func (m *MockOTelGRPCCollector) QueuePartialSuccesses(partials ...otlp.PartialSuccess) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.partialSuccesses = append(m.partialSuccesses, partials...)
+}
+
+func (m *MockOTelGRPCCollector) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors = nil
+	m.partialSuccesses = nil
+	m.ReceivedTraces = make([]map[string]any, 0)
+	m.ReceivedLogs = make([]map[string]any, 0)
+	m.ReceivedMetrics = make([]map[string]any, 0)
+	m.ReceivedMetadata = make([]metadata.MD, 0)
+	m.RequestCount = 0
+	m.ShouldFail = false
+}
+
+func (m *MockOTelGRPCCollector) GetStats() (traces, logs, metrics, total int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.ReceivedTraces), len(m.ReceivedLogs), len(m.ReceivedMetrics), m.RequestCount
+}
@@ -0,0 +1,41 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	coltrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestMockOTelGRPCCollector(t *testing.T) {
+	mock := NewMockOTelGRPCCollector()
+	defer mock.Close()
+
+	conn, err := grpc.NewClient(mock.Endpoint(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+	client := coltrace.NewTraceServiceClient(conn)
+	req := &coltrace.ExportTraceServiceRequest{ResourceSpans: []*tracev1.ResourceSpans{{}}}
+
+	if _, err := client.Export(context.Background(), req); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	traces, logs, metrics, total := mock.GetStats()
+	if traces != 1 || logs != 0 || metrics != 0 || total != 1 {
+		t.Errorf("Expected 1 trace request, got traces=%d logs=%d metrics=%d total=%d", traces, logs, metrics, total)
+	}
+
+	mock.Reset()
+	mock.ShouldFail = true
+	if _, err := client.Export(context.Background(), req); err == nil {
+		t.Error("Expected Export to fail when ShouldFail is set")
+	}
+	if traces, _, _, total := mock.GetStats(); traces != 0 || total != 1 {
+		t.Errorf("Expected failed request to be counted but not stored, got traces=%d total=%d", traces, total)
+	}
+}
@@ -0,0 +1,73 @@
+package transform
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// idFields are the OTLP/JSON fields holding hex encoded trace and span IDs. Spans,
+// span links, log records and exemplars all use these names.
+var idFields = map[string]bool{
+	"traceId":      true,
+	"spanId":       true,
+	"parentSpanId": true,
+}
+
+// IDRemapper replaces every trace and span ID with a fresh random ID of the same size.
+// An ID is always replaced by the same new ID, so parent/child relations and links hold
+// across every line it rewrites. Empty, all-zero and malformed IDs are left as they are.
+// It is safe for concurrent use.
+type IDRemapper struct {
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+// NewIDRemapper returns an IDRemapper with an empty mapping
+func NewIDRemapper() *IDRemapper {
+	return &IDRemapper{ids: make(map[string]string)}
+}
+
+// Apply replaces the IDs of data
+func (m *IDRemapper) Apply(data s.TelemetryData) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	walk(data, func(obj map[string]any, key string, field any) {
+		if id, ok := field.(string); ok && idFields[key] {
+			obj[key] = m.remap(id)
+		}
+	})
+}
+
+// remap returns the new ID for id, generating it on first sight
+func (m *IDRemapper) remap(id string) string {
+	original := strings.ToLower(id)
+	raw, err := hex.DecodeString(original)
+	if err != nil || len(raw) == 0 || isZero(raw) {
+		return id
+	}
+	if mapped, ok := m.ids[original]; ok {
+		return mapped
+	}
+	fresh := make([]byte, len(raw))
+	for isZero(fresh) {
+		if _, err := rand.Read(fresh); err != nil {
+			return id
+		}
+	}
+	mapped := hex.EncodeToString(fresh)
+	m.ids[original] = mapped
+	return mapped
+}
+
+func isZero(id []byte) bool {
+	for _, b := range id {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
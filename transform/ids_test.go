@@ -0,0 +1,85 @@
+package transform
+
+import (
+	"testing"
+)
+
+const (
+	traceID  = "5b8efff798038103d269b633813fc60c"
+	parentID = "eee19b7ec3c1b174"
+	childID  = "eee19b7ec3c1b173"
+)
+
+const parentLine = `{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"` + traceID + `","spanId":"` + parentID + `","parentSpanId":""}]}]}]}`
+
+const childLine = `{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"5B8EFFF798038103D269B633813FC60C","spanId":"` + childID + `","parentSpanId":"` + parentID + `",` +
+	`"links":[{"traceId":"` + traceID + `","spanId":"` + parentID + `"}]}]}]}],` +
+	`"resourceLogs":[{"scopeLogs":[{"logRecords":[{"traceId":"` + traceID + `","spanId":"` + childID + `"}]}]}]}`
+
+func span(data map[string]any, resourceKey, scopeKey, itemKey string) map[string]any {
+	resource := data[resourceKey].([]any)[0].(map[string]any)
+	scope := resource[scopeKey].([]any)[0].(map[string]any)
+	return scope[itemKey].([]any)[0].(map[string]any)
+}
+
+func TestIDRemapperKeepsRelationships(t *testing.T) {
+	remapper := NewIDRemapper()
+	parentData, childData := parse(t, parentLine), parse(t, childLine)
+	remapper.Apply(parentData)
+	remapper.Apply(childData)
+
+	parent := span(parentData, "resourceSpans", "scopeSpans", "spans")
+	child := span(childData, "resourceSpans", "scopeSpans", "spans")
+	link := child["links"].([]any)[0].(map[string]any)
+	log := span(childData, "resourceLogs", "scopeLogs", "logRecords")
+
+	newTrace, newParent, newChild := parent["traceId"], parent["spanId"], child["spanId"]
+	if newTrace == traceID || newParent == parentID || newChild == childID {
+		t.Fatalf("Expected fresh IDs, got trace=%v parent=%v child=%v", newTrace, newParent, newChild)
+	}
+	if len(newTrace.(string)) != len(traceID) || len(newParent.(string)) != len(parentID) {
+		t.Errorf("Expected IDs to keep their size, got trace=%v span=%v", newTrace, newParent)
+	}
+	if newParent == newChild {
+		t.Errorf("Expected different spans to get different IDs, both got %v", newParent)
+	}
+	if parent["parentSpanId"] != "" {
+		t.Errorf("Expected the empty parent of a root span to stay empty, got %v", parent["parentSpanId"])
+	}
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"child traceId (upper case in the input)", child["traceId"], newTrace},
+		{"child parentSpanId", child["parentSpanId"], newParent},
+		{"link traceId", link["traceId"], newTrace},
+		{"link spanId", link["spanId"], newParent},
+		{"log traceId", log["traceId"], newTrace},
+		{"log spanId", log["spanId"], newChild},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("Expected %s to be %v, got %v", check.name, check.want, check.got)
+		}
+	}
+}
+
+func TestIDRemapperFreshPerRun(t *testing.T) {
+	first, second := parse(t, parentLine), parse(t, parentLine)
+	NewIDRemapper().Apply(first)
+	NewIDRemapper().Apply(second)
+	a := span(first, "resourceSpans", "scopeSpans", "spans")["traceId"]
+	b := span(second, "resourceSpans", "scopeSpans", "spans")["traceId"]
+	if a == b {
+		t.Errorf("Expected separate runs to get different IDs, both got %v", a)
+	}
+}
+
+func TestIDRemapperLeavesInvalidIDs(t *testing.T) {
+	data := parse(t, `{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"00000000000000000000000000000000","spanId":"not-hex"}]}]}]}`)
+	NewIDRemapper().Apply(data)
+	got := span(data, "resourceSpans", "scopeSpans", "spans")
+	if got["traceId"] != "00000000000000000000000000000000" || got["spanId"] != "not-hex" {
+		t.Errorf("Expected invalid IDs to be left as they are, got %v", got)
+	}
+}
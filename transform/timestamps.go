@@ -0,0 +1,100 @@
+package transform
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/laiambryant/telemetry-ingestor/config"
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// timestampFields are the OTLP/JSON fields holding Unix nanosecond timestamps. Span
+// events, exemplars and metric data points use the same names.
+var timestampFields = map[string]bool{
+	"startTimeUnixNano":    true,
+	"endTimeUnixNano":      true,
+	"timeUnixNano":         true,
+	"observedTimeUnixNano": true,
+}
+
+// TimestampRange is the span of the timestamps seen in a set of lines. Zero
+// timestamps mean unset in OTLP and are ignored.
+type TimestampRange struct {
+	Oldest int64
+	Newest int64
+	Found  bool
+}
+
+// Observe widens the range with the timestamps of data
+func (r *TimestampRange) Observe(data s.TelemetryData) {
+	walk(data, func(_ map[string]any, key string, field any) {
+		if !timestampFields[key] {
+			return
+		}
+		ts, ok := timestamp(field)
+		if !ok || ts == 0 {
+			return
+		}
+		if !r.Found || ts < r.Oldest {
+			r.Oldest = ts
+		}
+		if !r.Found || ts > r.Newest {
+			r.Newest = ts
+		}
+		r.Found = true
+	})
+}
+
+// Anchor returns the timestamp of the range selected by anchor, the newest by default
+func (r TimestampRange) Anchor(anchor string) int64 {
+	if anchor == config.REBASE_ANCHOR_OLDEST {
+		return r.Oldest
+	}
+	return r.Newest
+}
+
+// Rebaser shifts every timestamp by the same amount, keeping the spacing between records
+type Rebaser struct {
+	Shift int64
+}
+
+// NewRebaser returns a Rebaser moving the anchor timestamp of r to target
+func NewRebaser(r TimestampRange, anchor string, target time.Time) *Rebaser {
+	if !r.Found {
+		return &Rebaser{}
+	}
+	return &Rebaser{Shift: target.UnixNano() - r.Anchor(anchor)}
+}
+
+// Apply shifts the timestamps of data. Shifted values are written as decimal strings,
+// the OTLP/JSON encoding of 64-bit integers, so no precision is lost.
+func (rb *Rebaser) Apply(data s.TelemetryData) {
+	if rb.Shift == 0 {
+		return
+	}
+	walk(data, func(obj map[string]any, key string, field any) {
+		if !timestampFields[key] {
+			return
+		}
+		if ts, ok := timestamp(field); ok && ts != 0 {
+			obj[key] = strconv.FormatInt(max(ts+rb.Shift, 1), 10)
+		}
+	})
+}
+
+// timestamp reads a nanosecond timestamp encoded as a decimal string or a JSON number
+func timestamp(field any) (int64, bool) {
+	switch v := field.(type) {
+	case string:
+		ts, err := strconv.ParseInt(v, 10, 64)
+		return ts, err == nil
+	case float64:
+		if v < 0 || v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,95 @@
+package transform
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	c "github.com/laiambryant/gotestutils/ctesting"
+	"github.com/laiambryant/telemetry-ingestor/config"
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+const spanLine = `{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"01","startTimeUnixNano":"1000","endTimeUnixNano":"3000",` +
+	`"events":[{"timeUnixNano":"2000","name":"retry"}],"links":[{"traceId":"02","spanId":"03"}]}]}]}]}`
+
+const logAndMetricLine = `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"timeUnixNano":0,"observedTimeUnixNano":5000}]}]}],` +
+	`"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"sum":{"dataPoints":[{"startTimeUnixNano":"0","timeUnixNano":"4000",` +
+	`"exemplars":[{"timeUnixNano":"3500"}]}]}}]}]}]}`
+
+func parse(t *testing.T, line string) s.TelemetryData {
+	t.Helper()
+	var data s.TelemetryData
+	if err := json.Unmarshal([]byte(line), &data); err != nil {
+		t.Fatalf("failed to parse %s: %v", line, err)
+	}
+	return data
+}
+
+func encode(t *testing.T, data s.TelemetryData) string {
+	t.Helper()
+	out, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	return string(out)
+}
+
+func TestTimestampRange(t *testing.T) {
+	observe := func(lines ...string) func() (TimestampRange, error) {
+		return func() (TimestampRange, error) {
+			var r TimestampRange
+			for _, line := range lines {
+				r.Observe(parse(t, line))
+			}
+			return r, nil
+		}
+	}
+	tests := []c.CharacterizationTest[TimestampRange]{
+		c.NewCharacterizationTest(TimestampRange{Oldest: 1000, Newest: 3000, Found: true}, nil, observe(spanLine)),
+		// Zero timestamps are unset and JSON numbers count as well as strings
+		c.NewCharacterizationTest(TimestampRange{Oldest: 3500, Newest: 5000, Found: true}, nil, observe(logAndMetricLine)),
+		c.NewCharacterizationTest(TimestampRange{Oldest: 1000, Newest: 5000, Found: true}, nil, observe(logAndMetricLine, spanLine)),
+		c.NewCharacterizationTest(TimestampRange{}, nil, observe(`{"resourceSpans":[]}`)),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestRebaserApply(t *testing.T) {
+	r := TimestampRange{Oldest: 1000, Newest: 5000, Found: true}
+	target := time.Unix(0, 1_000_000)
+	rebase := func(anchor, line string) func() (string, error) {
+		return func() (string, error) {
+			data := parse(t, line)
+			Chain{NewRebaser(r, anchor, target)}.Apply(data)
+			return encode(t, data), nil
+		}
+	}
+	tests := []c.CharacterizationTest[string]{
+		c.NewCharacterizationTest(
+			`{"resourceSpans":[{"scopeSpans":[{"spans":[{"endTimeUnixNano":"998000","events":[{"name":"retry","timeUnixNano":"997000"}],`+
+				`"links":[{"spanId":"03","traceId":"02"}],"startTimeUnixNano":"996000","traceId":"01"}]}]}]}`,
+			nil, rebase(config.REBASE_ANCHOR_NEWEST, spanLine)),
+		c.NewCharacterizationTest(
+			`{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"observedTimeUnixNano":"1004000","timeUnixNano":0}]}]}],`+
+				`"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"sum":{"dataPoints":[{"exemplars":[{"timeUnixNano":"1002500"}],`+
+				`"startTimeUnixNano":"0","timeUnixNano":"1003000"}]}}]}]}]}`,
+			nil, rebase(config.REBASE_ANCHOR_OLDEST, logAndMetricLine)),
+	}
+	c.VerifyCharacterizationTestsAndResults(t, tests, false)
+}
+
+func TestRebaserKeepsNanosecondPrecision(t *testing.T) {
+	data := parse(t, `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"timeUnixNano":"1700000000123456789"}]}]}]}`)
+	r := TimestampRange{Oldest: 1700000000123456789, Newest: 1700000000123456789, Found: true}
+	NewRebaser(r, config.REBASE_ANCHOR_NEWEST, time.Unix(1800000000, 1)).Apply(data)
+	if got := encode(t, data); got != `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"timeUnixNano":"1800000000000000001"}]}]}]}` {
+		t.Errorf("Expected the anchor to land exactly on the target, got %s", got)
+	}
+}
+
+func TestNewRebaserWithoutTimestamps(t *testing.T) {
+	if rb := NewRebaser(TimestampRange{}, config.REBASE_ANCHOR_NEWEST, time.Now()); rb.Shift != 0 {
+		t.Errorf("Expected no shift without timestamps, got %d", rb.Shift)
+	}
+}
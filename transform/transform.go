@@ -0,0 +1,38 @@
+package transform
+
+import (
+	s "github.com/laiambryant/telemetry-ingestor/structs"
+)
+
+// Transform rewrites a parsed telemetry line in place before any job is built from it
+type Transform interface {
+	Apply(data s.TelemetryData)
+}
+
+// Chain applies its transforms in order. A nil Chain leaves lines untouched.
+type Chain []Transform
+
+// Apply runs every transform of the chain on data
+func (c Chain) Apply(data s.TelemetryData) {
+	for _, t := range c {
+		t.Apply(data)
+	}
+}
+
+// walk calls visit for every field of every object nested in value. visit may replace
+// the field in obj.
+func walk(value any, visit func(obj map[string]any, key string, field any)) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			visit(v, key, field)
+			walk(v[key], visit)
+		}
+	case s.TelemetryData:
+		walk(map[string]any(v), visit)
+	case []any:
+		for _, item := range v {
+			walk(item, visit)
+		}
+	}
+}